@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/bench"
+	requestchain "github.com/susiteemu/startpoint/core/chaining"
+	"github.com/susiteemu/startpoint/core/loader"
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/print"
+	"github.com/susiteemu/startpoint/tui/styles"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type BenchConfig struct {
+	Plain       bool
+	Requests    int
+	Concurrency int
+	Duration    time.Duration
+}
+
+var benchConfig BenchConfig
+
+var benchCmd = &cobra.Command{
+	Use:   "bench [REQUEST NAME] [PROFILE NAME]",
+	Short: "Run a http request from workspace repeatedly and report latencies",
+	Long:  `Run a http request (and its previous requests) from workspace repeatedly and concurrently. Reports throughput, error rate, status codes and latency percentiles of the request.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.RangeArgs(1, 2)(cmd, args); err != nil {
+			return err
+		}
+		if len(ParseArgs(args).Request) == 0 {
+			return errors.New("Request name is required")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		benchArgs := ParseArgs(args)
+		workspace := viper.GetString("workspace")
+
+		requests, err := loader.ReadRequests(workspace)
+		if err != nil {
+			fmt.Print(fmt.Errorf("error %v", err))
+			return
+		}
+		var request *model.RequestMold
+		for _, m := range requests {
+			if m.Name == benchArgs.Request {
+				request = m
+				break
+			}
+		}
+		if request == nil {
+			fmt.Printf("Could not find a request with name '%s' under workspace '%s'", benchArgs.Request, workspace)
+			return
+		}
+
+		profile, err := loadProfile(workspace, benchArgs.Profile)
+		if err != nil {
+			fmt.Print(fmt.Errorf("error %v", err))
+			return
+		}
+
+		opts := bench.Options{
+			Requests:    benchConfig.Requests,
+			Concurrency: benchConfig.Concurrency,
+			Duration:    benchConfig.Duration,
+		}
+		// with only duration given, run until it elapses
+		if opts.Duration > 0 && !cmd.Flags().Changed("requests") {
+			opts.Requests = 0
+		}
+
+		chain := requestchain.ResolveRequestChain(request, requests)
+		log.Info().Msgf("Starting to bench %s with %v", request.Name, opts)
+		fmt.Printf("Running %s with concurrency %d...\n\n", request.Name, opts.Concurrency)
+		report, err := bench.Run(chain, profile, opts)
+		if err != nil {
+			fmt.Print(fmt.Errorf("error %v", err))
+			return
+		}
+
+		styles.LoadTheme()
+		printed, prettyPrinted, err := print.SprintBenchReport(report, !benchConfig.Plain)
+		if err != nil {
+			fmt.Print(fmt.Errorf("error %v", err))
+			return
+		}
+		if benchConfig.Plain {
+			fmt.Println(printed)
+		} else {
+			fmt.Println(prettyPrinted)
+		}
+	},
+	ValidArgsFunction: runCmd.ValidArgsFunction,
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	benchCmd.PersistentFlags().BoolVarP(&benchConfig.Plain, "plain", "p", false, "Print plain report without styling")
+	benchCmd.PersistentFlags().IntVarP(&benchConfig.Requests, "requests", "n", 100, "Number of requests to run")
+	benchCmd.PersistentFlags().IntVarP(&benchConfig.Concurrency, "concurrency", "c", 1, "Number of requests to run concurrently")
+	benchCmd.PersistentFlags().DurationVar(&benchConfig.Duration, "duration", 0, "Run for at most this long, e.g. 30s")
+}
@@ -22,6 +22,10 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/susiteemu/startpoint/core/import/graphql"
 	"github.com/susiteemu/startpoint/core/import/openapi"
 
 	"github.com/rs/zerolog/log"
@@ -30,17 +34,35 @@ import (
 )
 
 type ImportConfig struct {
-	Path string
+	Path    string
+	GraphQL string
+	Headers []string
 }
 
 var importConfig ImportConfig
 
 var importCmd = &cobra.Command{
 	Use:   "import",
-	Short: "Import workspace from OpenAPI Spec v3",
+	Short: "Import workspace from OpenAPI Spec v3 or GraphQL schema",
 	Run: func(cmd *cobra.Command, args []string) {
 		log.Info().Msgf("Called import cmd with %v", importConfig)
 		workspace := viper.GetString("workspace")
+		if importConfig.GraphQL != "" {
+			headers := map[string]string{}
+			for _, header := range importConfig.Headers {
+				name, value, found := strings.Cut(header, ":")
+				if !found {
+					fmt.Printf("Invalid header %s, expected format is Name: value\n", header)
+					return
+				}
+				headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+			err := graphql.ReadSchema(importConfig.GraphQL, headers, workspace)
+			if err != nil {
+				fmt.Print(fmt.Errorf("error %v", err))
+			}
+			return
+		}
 		openapi.ReadSpec(importConfig.Path, workspace)
 	},
 }
@@ -49,4 +71,6 @@ func init() {
 	rootCmd.AddCommand(importCmd)
 
 	importCmd.PersistentFlags().StringVarP(&importConfig.Path, "path", "p", "", "OpenAPI Spec v3 location (filepath or url)")
+	importCmd.PersistentFlags().StringVarP(&importConfig.GraphQL, "graphql", "g", "", "GraphQL endpoint to introspect or file with introspection result")
+	importCmd.PersistentFlags().StringArrayVarP(&importConfig.Headers, "header", "H", []string{}, "Header sent with introspection query, e.g. \"Authorization: Bearer token\"")
 }
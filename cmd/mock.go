@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/susiteemu/startpoint/core/loader"
+	"github.com/susiteemu/startpoint/core/mock"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type MockConfig struct {
+	Port int
+}
+
+var mockConfig MockConfig
+
+var mockCmd = &cobra.Command{
+	Use:   "mock [PROFILE NAME]",
+	Short: "Serve mocked responses for requests in workspace",
+	Long:  `Serve mocked responses for requests in workspace. Responses come from examples saved to requests or from the latest recorded history entry of a request.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		workspace := viper.GetString("workspace")
+		requests, err := loader.ReadRequests(workspace)
+		if err != nil {
+			fmt.Print(fmt.Errorf("error %v", err))
+			return
+		}
+
+		profileName := ""
+		if len(args) > 0 {
+			profileName = args[0]
+		}
+		profile, err := loadProfile(workspace, profileName)
+		if err != nil {
+			fmt.Print(fmt.Errorf("error %v", err))
+			return
+		}
+
+		routes := mock.NewRoutes(requests, profile)
+		if len(routes) == 0 {
+			fmt.Printf("No requests with examples or history under workspace '%s'\n", workspace)
+			return
+		}
+		for _, route := range routes {
+			fmt.Printf("%-7s %s (%s)\n", route.Method, route.Path, route.RequestName)
+		}
+
+		server := mock.NewServer(routes, func(r *http.Request, status int, route *mock.Route, source string) {
+			if route == nil {
+				fmt.Printf("%d %s %s\n", status, r.Method, r.URL.Path)
+				return
+			}
+			fmt.Printf("%d %s %s -> %s (%s)\n", status, r.Method, r.URL.Path, route.RequestName, source)
+		})
+
+		addr := fmt.Sprintf(":%d", mockConfig.Port)
+		fmt.Printf("\nServing %d mocked requests on %s\n", len(routes), addr)
+		log.Info().Msgf("Starting mock server on %s with %d routes", addr, len(routes))
+		err = http.ListenAndServe(addr, server)
+		if err != nil {
+			log.Error().Err(err).Msg("Mock server failed")
+			fmt.Print(fmt.Errorf("error %v", err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mockCmd)
+
+	mockCmd.PersistentFlags().IntVar(&mockConfig.Port, "port", 8080, "Port to listen on")
+}
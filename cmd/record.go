@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/record"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type RecordConfig struct {
+	Listen string
+	Target string
+}
+
+var recordConfig RecordConfig
+
+var recordCmd = &cobra.Command{
+	Use:   "record [PROFILE NAME]",
+	Short: "Record proxied traffic into requests in workspace",
+	Long:  `Run a reverse proxy that forwards traffic to target and writes each distinct request into workspace. Values of the profile are replaced with template variables in recorded requests.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+			return err
+		}
+		if len(recordConfig.Target) == 0 {
+			return errors.New("Target is required")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		workspace := viper.GetString("workspace")
+
+		profileName := ""
+		if len(args) > 0 {
+			profileName = args[0]
+		}
+		profile, err := loadProfile(workspace, profileName)
+		if err != nil {
+			fmt.Print(fmt.Errorf("error %v", err))
+			return
+		}
+
+		recorder, err := record.New(recordConfig.Target, workspace, profile, func(mold *model.RequestMold, err error) {
+			status := "OK"
+			if err != nil {
+				status = "ERROR"
+			}
+			fmt.Printf("[%s] %s\n", status, filepath.Join(mold.Root, mold.Filename))
+		})
+		if err != nil {
+			fmt.Print(fmt.Errorf("error %v", err))
+			return
+		}
+
+		fmt.Printf("Recording requests proxied from %s to %s\n\n", recordConfig.Listen, recordConfig.Target)
+		log.Info().Msgf("Starting recording proxy on %s with target %s", recordConfig.Listen, recordConfig.Target)
+		err = http.ListenAndServe(recordConfig.Listen, recorder)
+		if err != nil {
+			log.Error().Err(err).Msg("Recording proxy failed")
+			fmt.Print(fmt.Errorf("error %v", err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+
+	recordCmd.PersistentFlags().StringVar(&recordConfig.Listen, "listen", ":8888", "Address to listen on")
+	recordCmd.PersistentFlags().StringVar(&recordConfig.Target, "target", "", "Url to forward traffic to")
+}
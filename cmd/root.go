@@ -4,11 +4,14 @@ Copyright © 2023 Teemu Turunen <teturun@gmail.com>
 package cmd
 
 import (
+	"github.com/susiteemu/startpoint/core/client"
 	"github.com/susiteemu/startpoint/core/configuration"
 	"github.com/susiteemu/startpoint/core/writer"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	mainview "github.com/susiteemu/startpoint/tui"
 
@@ -44,7 +47,17 @@ var rootCmd = &cobra.Command{
 }
 
 func Execute() {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupts
+		client.RemoveAllBodyFiles()
+		os.Exit(1)
+	}()
+
 	err := rootCmd.Execute()
+	// large response bodies still kept, e.g. by the TUI, are not needed after the command
+	client.RemoveAllBodyFiles()
 	if err != nil {
 		os.Exit(1)
 	}
@@ -6,15 +6,19 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
 
 	requestchain "github.com/susiteemu/startpoint/core/chaining"
+	"github.com/susiteemu/startpoint/core/client"
 	"github.com/susiteemu/startpoint/core/client/runner"
 	"github.com/susiteemu/startpoint/core/loader"
 	"github.com/susiteemu/startpoint/core/model"
 	"github.com/susiteemu/startpoint/core/print"
+	"github.com/susiteemu/startpoint/core/session"
+	"github.com/susiteemu/startpoint/core/tools/paths"
 	"github.com/susiteemu/startpoint/tui/styles"
 
 	"github.com/rs/zerolog/log"
@@ -27,6 +31,8 @@ type RunConfig struct {
 	PrintHeaders   bool
 	PrintBody      bool
 	PrintTraceInfo bool
+	Session        string
+	Body           string
 }
 
 type RunArgs struct {
@@ -75,55 +81,94 @@ var runCmd = &cobra.Command{
 			return
 		}
 
-		profiles, err := loader.ReadProfiles(viper.GetString("workspace"))
+		// body given with --body replaces the body of the request
+		var body model.Body
+		if cmd.Flags().Changed("body") {
+			body, err = readBodyArg(runConfig.Body, os.Stdin, viper.GetString("workspace"))
+			if err != nil {
+				fmt.Print(fmt.Errorf("error %v", err))
+				return
+			}
+		}
+
+		profile, err := loadProfile(viper.GetString("workspace"), runArgs.Profile)
 		if err != nil {
 			fmt.Print(fmt.Errorf("error %v", err))
 			return
 		}
-		profileName := runArgs.Profile
-		if len(profileName) == 0 {
-			profileName = "default"
-		}
-		var profile *model.Profile
-		envVars := os.Environ()
-		for _, p := range profiles {
-			if p.Name == profileName {
-				profile = &model.Profile{
-					Name:      p.Name,
-					Variables: loader.GetProfileValues(p, profiles, envVars),
-				}
-				break
+
+		sess := session.New()
+		if len(runConfig.Session) > 0 {
+			sess, err = session.Load(viper.GetString("workspace"), runConfig.Session)
+			if err != nil {
+				fmt.Print(fmt.Errorf("error %v", err))
+				return
 			}
 		}
 
+		// load theme
+		styles.LoadTheme()
+
 		runRequests := requestchain.ResolveRequestChain(request, requests)
-		responses, err := runner.RunRequestChain(runRequests, profile, func(took time.Duration, statusCode int) {
+		responses, err := runner.RunRequestChainWithOptions(runRequests, profile, sess, runner.Options{Body: body}, func(took time.Duration, statusCode int) {
 			log.Info().Msgf("Request responded with status %d and took %s", statusCode, took)
+		}, func(requestName string, event model.Event) {
+			if !runConfig.PrintBody {
+				return
+			}
+			eventStr, prettyEventStr, err := print.SprintEvent(event, !runConfig.Plain)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to print event of %s", requestName)
+				return
+			}
+			if runConfig.Plain {
+				fmt.Println(eventStr)
+			} else {
+				fmt.Println(prettyEventStr)
+			}
 		})
+		// cookies of successful requests are kept even if the chain fails
+		if saveErr := sess.Save(); saveErr != nil {
+			log.Error().Err(saveErr).Msgf("Failed to save session %s", runConfig.Session)
+		}
 		if err != nil {
 			fmt.Print(fmt.Errorf("error %v", err))
 			return
 		}
 
-		// load theme
-		styles.LoadTheme()
 		for _, response := range responses {
 			printOpts := print.PrintOpts{
-				PrettyPrint:    !runConfig.Plain,
-				PrintHeaders:   runConfig.PrintHeaders,
-				PrintBody:      runConfig.PrintBody,
+				PrettyPrint:  !runConfig.Plain,
+				PrintHeaders: runConfig.PrintHeaders,
+				// events of a stream were printed as they arrived
+				PrintBody:      runConfig.PrintBody && len(response.Events) == 0,
 				PrintTraceInfo: runConfig.PrintTraceInfo,
 			}
+			// a body too large to keep in memory is copied from its file as is
+			copyBody := printOpts.PrintBody && len(response.BodyFile) > 0
+			if copyBody {
+				printOpts.PrintBody = false
+			}
 			responseStr, prettyResponseStr, err := print.SprintResponse(response, printOpts)
 			if err != nil {
 				fmt.Print(fmt.Errorf("error %v", err))
 				return
 			}
-			if printOpts.PrettyPrint {
-				fmt.Println(prettyResponseStr)
-			} else {
-				fmt.Println(responseStr)
+			if len(responseStr) > 0 {
+				if printOpts.PrettyPrint {
+					fmt.Println(prettyResponseStr)
+				} else {
+					fmt.Println(responseStr)
+				}
+			}
+			if copyBody {
+				err = copyBodyFile(response.BodyFile, os.Stdout)
+				if err != nil {
+					fmt.Print(fmt.Errorf("error %v", err))
+					return
+				}
 			}
+			client.RemoveBodyFile(response.BodyFile)
 		}
 
 	},
@@ -168,6 +213,58 @@ func ParseArgs(args []string) RunArgs {
 	return RunArgs{args[0], args[1]}
 }
 
+// copyBodyFile writes body of a response from the file it was written to
+func copyBodyFile(path string, out io.Writer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(out, file)
+	return err
+}
+
+// readBodyArg reads body given with --body: @- reads it from stdin, @path from a file relative to
+// the workspace and anything else is the body as is
+func readBodyArg(arg string, stdin io.Reader, workspace string) (string, error) {
+	if arg == "@-" {
+		body, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", err
+		}
+		return string(body), nil
+	}
+	if path, isFile := strings.CutPrefix(arg, "@"); isFile {
+		body, err := os.ReadFile(paths.Resolve(workspace, path))
+		if err != nil {
+			return "", err
+		}
+		return string(body), nil
+	}
+	return arg, nil
+}
+
+// loadProfile reads profiles from workspace and resolves the values of the named profile.
+// Empty name means the default profile. Returns nil profile if there is no such profile.
+func loadProfile(workspace string, profileName string) (*model.Profile, error) {
+	profiles, err := loader.ReadProfiles(workspace)
+	if err != nil {
+		return nil, err
+	}
+	if len(profileName) == 0 {
+		profileName = "default"
+	}
+	for _, p := range profiles {
+		if p.Name == profileName {
+			return &model.Profile{
+				Name:      p.Name,
+				Variables: loader.GetProfileValues(p, profiles, os.Environ()),
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
 func init() {
 	rootCmd.AddCommand(runCmd)
 
@@ -179,6 +276,8 @@ func init() {
 
 	runCmd.PersistentFlags().BoolVarP(&runConfig.Plain, "plain", "p", false, "Print plain response without styling")
 	runCmd.PersistentFlags().Bool("no-body", false, "Print no body")
+	runCmd.PersistentFlags().StringVar(&runConfig.Body, "body", "", "Replace body of the request: '@-' reads it from stdin and '@path' from a file")
+	runCmd.PersistentFlags().StringVar(&runConfig.Session, "session", "", "Name of a session persisting cookies and auth headers between runs")
 	runCmd.PersistentFlags().StringSlice("print", []string{}, fmt.Sprintf("Print WHAT\n- '%s'\tPrint response headers\n- '%s'\tPrint response body\n- '%s'\tPrint trace information", printHeadersP, printBodyP, printTrace))
 	runCmd.PreRun = func(cmd *cobra.Command, args []string) {
 		if cmd == runCmd {
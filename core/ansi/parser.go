@@ -55,3 +55,25 @@ func ParseANSI(input string, index int) (ColorState, error) {
 
 	return activeState, nil
 }
+
+// Strip removes ANSI color sequences from input. Returns the plain text and for each of its byte
+// indexes, and for its end, the corresponding index in input.
+func Strip(input string) (string, []int) {
+	var plainText strings.Builder
+	positions := make([]int, 0, len(input)+1)
+
+	lastPos := 0
+	for _, match := range ansiRegex.FindAllStringIndex(input, -1) {
+		for i := lastPos; i < match[0]; i++ {
+			positions = append(positions, i)
+		}
+		plainText.WriteString(input[lastPos:match[0]])
+		lastPos = match[1]
+	}
+	for i := lastPos; i < len(input); i++ {
+		positions = append(positions, i)
+	}
+	plainText.WriteString(input[lastPos:])
+	positions = append(positions, len(input))
+	return plainText.String(), positions
+}
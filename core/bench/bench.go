@@ -0,0 +1,207 @@
+package bench
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/client"
+	"github.com/susiteemu/startpoint/core/client/runner"
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/session"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Options struct {
+	// Requests is the total number of chain runs; zero or less means no limit (Duration must be set)
+	Requests    int
+	Concurrency int
+	// Duration limits how long the benchmark runs; zero means no limit (Requests must be set)
+	Duration time.Duration
+}
+
+type Phase struct {
+	Name      string
+	Latencies []time.Duration
+}
+
+type Report struct {
+	RequestName string
+	Total       int
+	Errors      int
+	Elapsed     time.Duration
+	StatusCodes map[int]int
+	Latencies   []time.Duration
+	Phases      []Phase
+}
+
+const (
+	PHASE_DNS    = "DNS"
+	PHASE_TCP    = "TCP"
+	PHASE_TLS    = "TLS"
+	PHASE_SERVER = "Server"
+)
+
+type sample struct {
+	err        bool
+	statusCode int
+	latency    time.Duration
+	traceInfo  model.TraceInfo
+}
+
+// Run runs the request chain repeatedly with given concurrency and collects the results of the last request in the chain.
+func Run(reqs []*model.RequestMold, profile *model.Profile, opts Options) (*Report, error) {
+	if len(reqs) == 0 {
+		return nil, errors.New("Requests must not be empty")
+	}
+	if opts.Requests <= 0 && opts.Duration <= 0 {
+		return nil, errors.New("Either number of requests or duration must be set")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		started  int
+		samples  []sample
+		deadline time.Time
+	)
+	start := time.Now()
+	if opts.Duration > 0 {
+		deadline = start.Add(opts.Duration)
+	}
+
+	// next reserves a run for a worker; returns false when the benchmark is done
+	next := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if opts.Requests > 0 && started >= opts.Requests {
+			return false
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return false
+		}
+		started++
+		return true
+	}
+
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// NOTE: building a request may modify the mold so each worker gets its own copies
+			chain := cloneChain(reqs)
+			for next() {
+				s := runOnce(chain, profile)
+				mu.Lock()
+				samples = append(samples, s)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return buildReport(reqs[len(reqs)-1].Name, samples, time.Since(start)), nil
+}
+
+func runOnce(chain []*model.RequestMold, profile *model.Profile) sample {
+	runStart := time.Now()
+	opts := runner.Options{
+		// latency phases come from trace info
+		RequestOptions: map[string]interface{}{"httpClient.enableTraceInfo": true},
+		// thousands of runs would only overwrite the same history entry
+		SkipHistory: true,
+	}
+	responses, err := runner.RunRequestChainWithOptions(chain, profile, session.New(), opts, func(took time.Duration, statusCode int) {}, nil)
+	// only the statistics of the responses are kept
+	for _, resp := range responses {
+		client.RemoveBodyFile(resp.BodyFile)
+	}
+	if err != nil || len(responses) != len(chain) {
+		log.Debug().Err(err).Msg("Benchmarked request chain failed")
+		return sample{err: true, latency: time.Since(runStart)}
+	}
+	resp := responses[len(responses)-1]
+	return sample{
+		err:        resp.StatusCode >= 400,
+		statusCode: resp.StatusCode,
+		latency:    resp.Time,
+		traceInfo:  resp.TraceInfo,
+	}
+}
+
+func cloneChain(reqs []*model.RequestMold) []*model.RequestMold {
+	chain := []*model.RequestMold{}
+	for _, r := range reqs {
+		c := r.Clone()
+		chain = append(chain, &c)
+	}
+	return chain
+}
+
+func buildReport(requestName string, samples []sample, elapsed time.Duration) *Report {
+	report := &Report{
+		RequestName: requestName,
+		Total:       len(samples),
+		Elapsed:     elapsed,
+		StatusCodes: map[int]int{},
+	}
+	dns := Phase{Name: PHASE_DNS}
+	tcp := Phase{Name: PHASE_TCP}
+	tls := Phase{Name: PHASE_TLS}
+	server := Phase{Name: PHASE_SERVER}
+	for _, s := range samples {
+		if s.err {
+			report.Errors++
+		}
+		if s.statusCode > 0 {
+			report.StatusCodes[s.statusCode]++
+			report.Latencies = append(report.Latencies, s.latency)
+			dns.Latencies = append(dns.Latencies, s.traceInfo.DNSLookup)
+			tcp.Latencies = append(tcp.Latencies, s.traceInfo.TCPConnTime)
+			tls.Latencies = append(tls.Latencies, s.traceInfo.TLSHandshake)
+			server.Latencies = append(server.Latencies, s.traceInfo.ServerTime)
+		}
+	}
+	report.Phases = []Phase{dns, tcp, tls, server}
+	return report
+}
+
+// Throughput returns completed runs per second.
+func (r *Report) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Total) / r.Elapsed.Seconds()
+}
+
+// ErrorRate returns the share of failed runs (transport errors and 4xx/5xx statuses) between 0 and 1.
+func (r *Report) ErrorRate() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.Total)
+}
+
+// Percentile returns the p:th (0-100) percentile of the latencies using nearest-rank method.
+func Percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
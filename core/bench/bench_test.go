@@ -0,0 +1,95 @@
+package bench
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1)%5 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	reqs := []*model.RequestMold{
+		{
+			Name: "Ping",
+			Type: model.CONTENT_TYPE_YAML,
+			Yaml: &model.YamlRequest{
+				Url:    server.URL,
+				Method: "GET",
+			},
+		},
+	}
+
+	report, err := Run(reqs, nil, Options{Requests: 20, Concurrency: 4})
+	assert.Nil(t, err)
+	assert.Equal(t, "Ping", report.RequestName)
+	assert.Equal(t, 20, report.Total)
+	assert.Equal(t, 4, report.Errors)
+	assert.Equal(t, map[int]int{200: 16, 503: 4}, report.StatusCodes)
+	assert.Equal(t, 20, len(report.Latencies))
+	assert.InDelta(t, 0.2, report.ErrorRate(), 0.0001)
+
+	_, err = Run(reqs, nil, Options{})
+	assert.NotNil(t, err)
+}
+
+func TestRunConcurrentlyKeepsRequestsAndHistoryAsIs(t *testing.T) {
+	var authorized atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer token" {
+			authorized.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	reqs := []*model.RequestMold{
+		{
+			Name: "Ping",
+			Type: model.CONTENT_TYPE_YAML,
+			Root: root,
+			Yaml: &model.YamlRequest{
+				Url:     server.URL,
+				Method:  "GET",
+				Headers: model.Headers{"Accept": {"*/*"}},
+				Auth:    model.Auth{Bearer: "token"},
+				Options: map[string]interface{}{"history": map[string]interface{}{"enabled": true}},
+			},
+		},
+	}
+
+	report, err := Run(reqs, nil, Options{Requests: 50, Concurrency: 8})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, report.Errors)
+	assert.Equal(t, int32(50), authorized.Load())
+	assert.Equal(t, model.Headers{"Accept": {"*/*"}}, reqs[0].Yaml.Headers)
+	assert.NoDirExists(t, filepath.Join(root, ".startpoint"))
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := []time.Duration{}
+	for i := 10; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, 5*time.Millisecond, Percentile(latencies, 50))
+	assert.Equal(t, 9*time.Millisecond, Percentile(latencies, 90))
+	assert.Equal(t, 10*time.Millisecond, Percentile(latencies, 99))
+	assert.Equal(t, 10*time.Millisecond, Percentile(latencies, 100))
+	assert.Equal(t, time.Duration(0), Percentile([]time.Duration{}, 50))
+}
@@ -2,18 +2,31 @@ package builder
 
 import (
 	b64 "encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
 	"github.com/susiteemu/startpoint/core/configuration"
 	"github.com/susiteemu/startpoint/core/model"
 	luang "github.com/susiteemu/startpoint/core/scripting/lua"
 	starlarkng "github.com/susiteemu/startpoint/core/scripting/starlark"
 	"github.com/susiteemu/startpoint/core/templating/templateng"
 	"github.com/susiteemu/startpoint/core/tools/conv"
+	"github.com/susiteemu/startpoint/core/tools/paths"
 
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
 )
 
+var pathParamRegex = regexp.MustCompile(`\{([^{}/?#]+)\}`)
+
 var builders = []func(requestMold *model.RequestMold, previousResponse *model.Response, profile model.Profile) (model.Request, bool, error){
 	buildYamlRequest,
 	buildScriptableRequest,
@@ -33,6 +46,7 @@ func BuildRequest(requestMold *model.RequestMold, profile model.Profile) (model.
 			break
 		}
 	}
+	request.Root = requestMold.Root
 	return request, nil
 }
 
@@ -48,6 +62,7 @@ func BuildRequestUsingPreviousResponse(requestMold *model.RequestMold, previousR
 			break
 		}
 	}
+	request.Root = requestMold.Root
 	return request, nil
 }
 
@@ -66,13 +81,21 @@ func buildYamlRequest(requestMold *model.RequestMold, _ *model.Response, profile
 		}
 		log.Debug().Msgf("Processed raw into %s", rawYaml)
 
-		yamlRequest = &model.YamlRequest{}
-		err := yaml.Unmarshal([]byte(rawYaml), yamlRequest)
-		if err != nil {
-			log.Error().Err(err).Msgf("Failed to unmarshal yaml %s", rawYaml)
-			return model.Request{}, false, err
+		if requestMold.Type == model.CONTENT_TYPE_GRAPHQL {
+			var err error
+			yamlRequest, err = model.ParseGraphQLDocument(rawYaml)
+			if err != nil {
+				return model.Request{}, false, err
+			}
+		} else {
+			yamlRequest = &model.YamlRequest{}
+			err := yaml.Unmarshal([]byte(rawYaml), yamlRequest)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to unmarshal yaml %s", rawYaml)
+				return model.Request{}, false, err
+			}
+			yamlRequest.Raw = rawYaml
 		}
-		yamlRequest.Raw = rawYaml
 
 		log.Debug().Msgf("Processed into yaml request %v", yamlRequest)
 	}
@@ -82,33 +105,91 @@ func buildYamlRequest(requestMold *model.RequestMold, _ *model.Response, profile
 		configuration.Flatten("", yamlRequest.Options, options)
 	}
 
+	// yaml request may be the one of the mold which must stay as it is when the request is
+	// modified, e.g. when the same mold is built concurrently
+	headers := yamlRequest.Headers.Clone()
+
+	var oauth2 *model.OAuth2Auth
+	var digest *model.DigestAuth
+	var awsSigV4 *model.AwsSigV4Auth
+	var apiKey *model.ApiKeyAuth
 	auth := yamlRequest.Auth
-	if auth != (model.Auth{}) {
-		if auth.Basic != (model.BasicAuth{}) {
-			if auth.Basic.User != "" && auth.Basic.Password != "" {
-				userPwd := fmt.Sprintf("%s:%s", auth.Basic.User, auth.Basic.Password)
-				base64encoded := b64.StdEncoding.EncodeToString([]byte(userPwd))
-				if yamlRequest.Headers == nil {
-					yamlRequest.Headers = model.Headers{}
-				}
-				yamlRequest.Headers[model.HEADER_NAME_AUTHORIZATION] = model.HeaderValues{fmt.Sprintf("%s %s", model.HEADER_VALUE_BASIC_AUTH, base64encoded)}
+	if auth.Basic != (model.BasicAuth{}) {
+		if auth.Basic.User != "" && auth.Basic.Password != "" {
+			userPwd := fmt.Sprintf("%s:%s", auth.Basic.User, auth.Basic.Password)
+			base64encoded := b64.StdEncoding.EncodeToString([]byte(userPwd))
+			if headers == nil {
+				headers = model.Headers{}
 			}
+			headers[model.HEADER_NAME_AUTHORIZATION] = model.HeaderValues{fmt.Sprintf("%s %s", model.HEADER_VALUE_BASIC_AUTH, base64encoded)}
+		}
 
-		} else if auth.Bearer != "" {
-			if yamlRequest.Headers == nil {
-				yamlRequest.Headers = model.Headers{}
-			}
-			yamlRequest.Headers[model.HEADER_NAME_AUTHORIZATION] = model.HeaderValues{fmt.Sprintf("%s %s", model.HEADER_VALUE_BEARER_AUTH, auth.Bearer)}
+	} else if auth.Bearer != "" {
+		if headers == nil {
+			headers = model.Headers{}
 		}
+		headers[model.HEADER_NAME_AUTHORIZATION] = model.HeaderValues{fmt.Sprintf("%s %s", model.HEADER_VALUE_BEARER_AUTH, auth.Bearer)}
+	} else if auth.Digest != (model.DigestAuth{}) {
+		// digest needs a challenge from server so it is handled when the request is sent
+		digest = &auth.Digest
+	} else if auth.AwsSigV4 != (model.AwsSigV4Auth{}) {
+		// signature covers the request as sent
+		awsSigV4 = &auth.AwsSigV4
+	} else if auth.ApiKey != (model.ApiKeyAuth{}) {
+		apiKey = &auth.ApiKey
+	} else if len(auth.OAuth2.TokenUrl) > 0 || len(auth.OAuth2.GrantType) > 0 {
+		// token is fetched when the request is sent
+		oauth2 = &auth.OAuth2
 	}
 
 	request := model.Request{
-		Url:     yamlRequest.Url,
-		Method:  yamlRequest.Method,
-		Headers: yamlRequest.Headers,
-		Body:    yamlRequest.Body,
-		Options: options,
-		Output:  yamlRequest.Output,
+		Url:      yamlRequest.Url,
+		Method:   yamlRequest.Method,
+		Headers:  headers,
+		Body:     yamlRequest.Body,
+		Options:  options,
+		Output:   yamlRequest.Output,
+		OAuth2:   oauth2,
+		Digest:   digest,
+		AwsSigV4: awsSigV4,
+	}
+
+	applyParams(yamlRequest.Query, yamlRequest.PathParams, &request)
+
+	if len(yamlRequest.BodyFile) > 0 {
+		if yamlRequest.Body != nil {
+			return model.Request{}, true, errors.New("body and body_file must not be used together")
+		}
+		body, err := readBodyFile(yamlRequest.BodyFile, yamlRequest.BodyFileTemplate, requestMold.Root, profile)
+		if err != nil {
+			return model.Request{}, true, err
+		}
+		request.Body = body
+	}
+
+	if requestMold.IsWebSocket() {
+		request.Protocol = model.PROTOCOL_WEBSOCKET
+		request.Messages = yamlRequest.Messages
+		request.Until = yamlRequest.Until
+	}
+
+	if yamlRequest.GraphQL != nil {
+		err := applyGraphQL(*yamlRequest.GraphQL, &request)
+		if err != nil {
+			return model.Request{}, true, err
+		}
+	}
+
+	if requestMold.IsGrpc() {
+		request.Protocol = model.PROTOCOL_GRPC
+		request.Grpc = resolveGrpcPaths(yamlRequest.Grpc, requestMold.Root)
+	}
+
+	if apiKey != nil {
+		err := applyApiKey(*apiKey, &request)
+		if err != nil {
+			return model.Request{}, true, err
+		}
 	}
 
 	return request, true, nil
@@ -159,32 +240,54 @@ func buildScriptableRequest(requestMold *model.RequestMold, previousResponse *mo
 		}
 	}
 
+	var oauth2 *model.OAuth2Auth
+	var digest *model.DigestAuth
+	var awsSigV4 *model.AwsSigV4Auth
+	var apiKey *model.ApiKeyAuth
 	authResult, has := res["auth"]
 	if has {
 		log.Debug().Msgf("Auth %v", authResult)
 		if authMap, ok := authResult.(map[string]interface{}); ok {
 			basicAuth, has := authMap["basic_auth"]
+			digestAuth, hasDigest := authMap["digest_auth"]
+			awsSigV4Result, hasAwsSigV4 := authMap["aws_sigv4"]
+			apiKeyResult, hasApiKey := authMap["api_key"]
+			oauth2Result, hasOAuth2 := authMap["oauth2"]
 			if has {
 				log.Debug().Msgf("Basic auth %T", basicAuth)
-				var username, password interface{}
-				var hasUser, hasPwd bool
-				basicAuthStringMap, ok := basicAuth.(map[string]interface{})
+				username, password, ok := scriptCredentials(basicAuth)
 				if ok {
-					username, hasUser = basicAuthStringMap["username"]
-					password, hasPwd = basicAuthStringMap["password"]
-				} else {
-					basicAuthAnyMap, ok := basicAuth.(map[interface{}]interface{})
-					if ok {
-						username, hasUser = basicAuthAnyMap["username"]
-						password, hasPwd = basicAuthAnyMap["password"]
-					}
-				}
-				if hasUser && hasPwd {
 					userPwd := fmt.Sprintf("%s:%s", username, password)
 					userPwdBytes := []byte(userPwd)
 					base64encoded := b64.StdEncoding.EncodeToString(userPwdBytes)
 					headers[model.HEADER_NAME_AUTHORIZATION] = []string{fmt.Sprintf("%s %s", model.HEADER_VALUE_BASIC_AUTH, base64encoded)}
 				}
+			} else if hasDigest {
+				username, password, ok := scriptCredentials(digestAuth)
+				if ok {
+					digest = &model.DigestAuth{
+						User:     fmt.Sprintf("%s", username),
+						Password: fmt.Sprintf("%s", password),
+					}
+				}
+			} else if hasAwsSigV4 {
+				awsSigV4, err = toAuth[model.AwsSigV4Auth](awsSigV4Result)
+				if err != nil {
+					log.Error().Err(err).Msgf("Auth aws_sigv4 %v is in invalid format", awsSigV4Result)
+					return model.Request{}, true, err
+				}
+			} else if hasApiKey {
+				apiKey, err = toAuth[model.ApiKeyAuth](apiKeyResult)
+				if err != nil {
+					log.Error().Err(err).Msgf("Auth api_key %v is in invalid format", apiKeyResult)
+					return model.Request{}, true, err
+				}
+			} else if hasOAuth2 {
+				oauth2, err = toAuth[model.OAuth2Auth](oauth2Result)
+				if err != nil {
+					log.Error().Err(err).Msgf("Auth oauth2 %v is in invalid format", oauth2Result)
+					return model.Request{}, true, err
+				}
 			} else {
 				bearerToken, has := authResult.(map[string]interface{})["bearer_token"]
 				if has {
@@ -226,16 +329,308 @@ func buildScriptableRequest(requestMold *model.RequestMold, previousResponse *mo
 		log.Warn().Err(err).Msg("Failed to convert body")
 	}
 
+	var query model.QueryParams
+	if queryResult, has := res["query"]; has && queryResult != nil {
+		query, err = scriptQueryParams(queryResult)
+		if err != nil {
+			log.Error().Err(err).Msgf("Query %v is in invalid format", queryResult)
+			return model.Request{}, true, err
+		}
+	}
+	var pathParams map[string]string
+	if pathParamsResult, has := res["path_params"]; has && pathParamsResult != nil {
+		pathParams, err = scriptPathParams(pathParamsResult)
+		if err != nil {
+			log.Error().Err(err).Msgf("Path params %v are in invalid format", pathParamsResult)
+			return model.Request{}, true, err
+		}
+	}
+
 	req := model.Request{
-		Url:     url,
-		Method:  method,
-		Headers: new(model.Headers).FromMap(headers),
-		Body:    body,
-		Options: options,
-		Output:  output,
+		Url:      url,
+		Method:   method,
+		Headers:  new(model.Headers).FromMap(headers),
+		Body:     body,
+		Options:  options,
+		Output:   output,
+		OAuth2:   oauth2,
+		Digest:   digest,
+		AwsSigV4: awsSigV4,
+	}
+
+	applyParams(query, pathParams, &req)
+
+	if apiKey != nil {
+		err := applyApiKey(*apiKey, &req)
+		if err != nil {
+			return model.Request{}, true, err
+		}
 	}
 
 	log.Debug().Msgf("Built request %v", req)
 
 	return req, true, nil
 }
+
+// applyApiKey places api key to headers, query or cookies of the request
+func applyApiKey(apiKey model.ApiKeyAuth, request *model.Request) error {
+	if len(apiKey.Name) == 0 {
+		return errors.New("api_key name must not be empty")
+	}
+	// headers may be shared with the request mold so they are copied before changing
+	headers := model.Headers{}
+	for k, v := range request.Headers {
+		headers[k] = v
+	}
+
+	switch apiKey.In {
+	case "", model.API_KEY_IN_HEADER:
+		headers[apiKey.Name] = model.HeaderValues{apiKey.Value}
+	case model.API_KEY_IN_QUERY:
+		request.Url = addQueryParam(request.Url, apiKey.Name, apiKey.Value)
+	case model.API_KEY_IN_COOKIE:
+		cookie := fmt.Sprintf("%s=%s", apiKey.Name, apiKey.Value)
+		if existing, has := headers[model.HEADER_NAME_COOKIE]; has && len(existing) > 0 {
+			// NOTE: cookies are delimited with semi-colon instead of comma
+			cookie = strings.Join(existing, "; ") + "; " + cookie
+		}
+		headers[model.HEADER_NAME_COOKIE] = model.HeaderValues{cookie}
+	default:
+		return fmt.Errorf("unsupported api_key in %s, expected one of header, query or cookie", apiKey.In)
+	}
+	request.Headers = headers
+	return nil
+}
+
+// addQueryParam appends url encoded query parameter to url keeping the rest of url as is
+func addQueryParam(rawUrl, name, value string) string {
+	fragment := ""
+	if idx := strings.Index(rawUrl, "#"); idx >= 0 {
+		rawUrl, fragment = rawUrl[:idx], rawUrl[idx:]
+	}
+	separator := "?"
+	if strings.Contains(rawUrl, "?") {
+		separator = "&"
+		if strings.HasSuffix(rawUrl, "?") || strings.HasSuffix(rawUrl, "&") {
+			separator = ""
+		}
+	}
+	return rawUrl + separator + url.QueryEscape(name) + "=" + url.QueryEscape(value) + fragment
+}
+
+// readBodyFile reads body from path relative to the workspace. Template variables of the profile
+// are filled when asked.
+func readBodyFile(path string, template bool, root string, profile model.Profile) (string, error) {
+	path = paths.Resolve(root, path)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to read body file %s", path)
+		return "", err
+	}
+	body := string(content)
+	if template {
+		for k, v := range profile.Variables {
+			body, _ = templateng.ProcessTemplateVariable(body, k, v)
+		}
+	}
+	return body, nil
+}
+
+// applyParams fills {name} sections of the url path with url encoded path params and appends
+// url encoded query params to the url in the order of their names
+func applyParams(query model.QueryParams, pathParams map[string]string, request *model.Request) {
+	if len(pathParams) > 0 {
+		used := map[string]bool{}
+		beforePath, path, afterPath := splitPath(request.Url)
+		path = pathParamRegex.ReplaceAllStringFunc(path, func(section string) string {
+			name := section[1 : len(section)-1]
+			value, has := pathParams[name]
+			if !has {
+				return section
+			}
+			used[name] = true
+			return url.PathEscape(value)
+		})
+		request.Url = beforePath + path + afterPath
+		for name := range pathParams {
+			if !used[name] {
+				// e.g. a profile variable with the same name has already filled it
+				log.Warn().Msgf("Path param %s has no {%s} in path of url %s", name, name, request.Url)
+			}
+		}
+		request.PathParams = pathParams
+	}
+
+	if len(query) > 0 {
+		names := make([]string, 0, len(query))
+		for name := range query {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			for _, value := range query[name] {
+				request.Url = addQueryParam(request.Url, name, value)
+			}
+		}
+		request.Query = query
+	}
+}
+
+// splitPath splits url to the scheme and authority before its path, the path and the query and
+// fragment after it. Url without a scheme, e.g. one still having a template for it, starts with
+// its path. Url is split by its syntax as templates in it may not be valid parts of an url yet.
+func splitPath(rawUrl string) (string, string, string) {
+	start := 0
+	if scheme, _, found := strings.Cut(rawUrl, "://"); found && !strings.ContainsAny(scheme, "/?#") {
+		authority := len(scheme) + len("://")
+		start = len(rawUrl)
+		if i := strings.IndexAny(rawUrl[authority:], "/?#"); i >= 0 {
+			start = authority + i
+		}
+	}
+	end := len(rawUrl)
+	if i := strings.IndexAny(rawUrl[start:], "?#"); i >= 0 {
+		end = start + i
+	}
+	return rawUrl[:start], rawUrl[start:end], rawUrl[end:]
+}
+
+// scriptQueryParams converts query returned by a script. A param is either a single value or a
+// list of values.
+func scriptQueryParams(value interface{}) (model.QueryParams, error) {
+	queryMap, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("query must be a map, got %T", value)
+	}
+	query := model.QueryParams{}
+	for name, paramValue := range queryMap {
+		if list, ok := paramValue.([]interface{}); ok {
+			values := model.QueryValues{}
+			for _, v := range list {
+				values = append(values, fmt.Sprint(v))
+			}
+			query[name] = values
+		} else {
+			query[name] = model.QueryValues{fmt.Sprint(paramValue)}
+		}
+	}
+	return query, nil
+}
+
+// scriptPathParams converts path params returned by a script
+func scriptPathParams(value interface{}) (map[string]string, error) {
+	paramsMap, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("path_params must be a map, got %T", value)
+	}
+	pathParams := map[string]string{}
+	for name, paramValue := range paramsMap {
+		pathParams[name] = fmt.Sprint(paramValue)
+	}
+	return pathParams, nil
+}
+
+// scriptCredentials reads username and password of auth returned by a script
+func scriptCredentials(auth interface{}) (interface{}, interface{}, bool) {
+	var username, password interface{}
+	var hasUser, hasPwd bool
+	authStringMap, ok := auth.(map[string]interface{})
+	if ok {
+		username, hasUser = authStringMap["username"]
+		password, hasPwd = authStringMap["password"]
+	} else {
+		authAnyMap, ok := auth.(map[interface{}]interface{})
+		if ok {
+			username, hasUser = authAnyMap["username"]
+			password, hasPwd = authAnyMap["password"]
+		}
+	}
+	return username, password, hasUser && hasPwd
+}
+
+// toAuth converts auth returned by a script into the same struct used by yaml requests
+func toAuth[T any](value interface{}) (*T, error) {
+	asYaml, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	auth := new(T)
+	err = yaml.Unmarshal(asYaml, auth)
+	if err != nil {
+		return nil, err
+	}
+	return auth, nil
+}
+
+// resolveGrpcPaths makes relative proto files and import paths relative to the request directory
+func resolveGrpcPaths(grpc *model.GrpcRequest, root string) *model.GrpcRequest {
+	if grpc == nil {
+		return &model.GrpcRequest{}
+	}
+	resolve := func(paths []string) []string {
+		var resolved []string
+		for _, path := range paths {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(root, path)
+			}
+			resolved = append(resolved, path)
+		}
+		return resolved
+	}
+	return &model.GrpcRequest{
+		Service:     grpc.Service,
+		Method:      grpc.Method,
+		ProtoFiles:  resolve(grpc.ProtoFiles),
+		ImportPaths: resolve(grpc.ImportPaths),
+	}
+}
+
+type graphQLPayload struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+}
+
+// applyGraphQL encodes the graphql request as json body or, for GET requests, as query
+// parameters. Method defaults to POST.
+func applyGraphQL(graphql model.GraphQLRequest, request *model.Request) error {
+	if request.Method == "" {
+		request.Method = http.MethodPost
+	}
+
+	if strings.ToUpper(request.Method) == http.MethodGet {
+		request.Url = addQueryParam(request.Url, "query", graphql.Query)
+		if len(graphql.Variables) > 0 {
+			variables, err := json.Marshal(graphql.Variables)
+			if err != nil {
+				return err
+			}
+			request.Url = addQueryParam(request.Url, "variables", string(variables))
+		}
+		if graphql.OperationName != "" {
+			request.Url = addQueryParam(request.Url, "operationName", graphql.OperationName)
+		}
+		request.Body = nil
+		return nil
+	}
+
+	body, err := json.Marshal(graphQLPayload{
+		Query:         graphql.Query,
+		Variables:     graphql.Variables,
+		OperationName: graphql.OperationName,
+	})
+	if err != nil {
+		return err
+	}
+	request.Body = string(body)
+
+	headers := model.Headers{}
+	for name, values := range request.Headers {
+		headers[name] = values
+	}
+	if _, ok := request.ContentType(); !ok {
+		headers[model.HEADER_NAME_CONTENT_TYPE] = model.HeaderValues{model.CONTENT_TYPE_APPLICATION_JSON}
+	}
+	request.Headers = headers
+	return nil
+}
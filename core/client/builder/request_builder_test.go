@@ -214,6 +214,359 @@ body:
 				Options: make(map[string]interface{}),
 			},
 		},
+		{
+			name: "Test with digest authentication",
+			mold: model.RequestMold{
+				Name: "yaml_request",
+				Yaml: &model.YamlRequest{
+					Url:     "http://foobar.com",
+					Method:  "GET",
+					Options: make(map[string]interface{}),
+					Auth: model.Auth{
+						Digest: model.DigestAuth{
+							User:     "jane",
+							Password: "doe",
+						},
+					},
+				},
+			},
+			profile: model.Profile{},
+			expected: model.Request{
+				Url:     "http://foobar.com",
+				Method:  "GET",
+				Options: make(map[string]interface{}),
+				Digest: &model.DigestAuth{
+					User:     "jane",
+					Password: "doe",
+				},
+			},
+		},
+		{
+			name: "Test with api key in query",
+			mold: model.RequestMold{
+				Name: "yaml_request",
+				Yaml: &model.YamlRequest{
+					Url:     "http://foobar.com/items?limit=10#top",
+					Method:  "GET",
+					Options: make(map[string]interface{}),
+					Auth: model.Auth{
+						ApiKey: model.ApiKeyAuth{
+							Name:  "api key",
+							Value: "a&b=c",
+							In:    "query",
+						},
+					},
+				},
+			},
+			profile: model.Profile{},
+			expected: model.Request{
+				Url:     "http://foobar.com/items?limit=10&api+key=a%26b%3Dc#top",
+				Method:  "GET",
+				Headers: model.Headers{},
+				Options: make(map[string]interface{}),
+			},
+		},
+
+		{
+			name: "Test with api key in cookie",
+			mold: model.RequestMold{
+				Name: "yaml_request",
+				Yaml: &model.YamlRequest{
+					Url:    "http://foobar.com",
+					Method: "GET",
+					Headers: model.Headers{
+						"Cookie": {"session=abc"},
+					},
+					Options: make(map[string]interface{}),
+					Auth: model.Auth{
+						ApiKey: model.ApiKeyAuth{
+							Name:  "key",
+							Value: "secret",
+							In:    "cookie",
+						},
+					},
+				},
+			},
+			profile: model.Profile{},
+			expected: model.Request{
+				Url:    "http://foobar.com",
+				Method: "GET",
+				Headers: model.Headers{
+					"Cookie": {"session=abc; key=secret"},
+				},
+				Options: make(map[string]interface{}),
+			},
+		},
+		{
+			name: "Test with websocket request and template variables",
+			mold: model.RequestMold{
+				Name:     "chat",
+				Filename: "chat.ws.yaml",
+				Yaml: &model.YamlRequest{
+					Url: "ws://{domain}/chat",
+					Raw: `url: ws://{domain}/chat
+headers:
+  X-Name: "{name}"
+messages:
+  - send: "hello {name}"
+    await: "^welcome"
+until: "^bye$"`,
+				},
+			},
+			profile: model.Profile{
+				Name: "test",
+				Variables: map[string]string{
+					"domain": "localhost:8080",
+					"name":   "jane",
+				},
+			},
+			expected: model.Request{
+				Url: "ws://localhost:8080/chat",
+				Headers: model.Headers{
+					"X-Name": {"jane"},
+				},
+				Options:  make(map[string]interface{}),
+				Protocol: model.PROTOCOL_WEBSOCKET,
+				Messages: []model.WebSocketMessage{
+					{Send: "hello jane", Await: "^welcome"},
+				},
+				Until: "^bye$",
+			},
+		},
+		{
+			name: "Test with graphql request",
+			mold: model.RequestMold{
+				Yaml: &model.YamlRequest{
+					Url: "http://foobar.com/graphql",
+					GraphQL: &model.GraphQLRequest{
+						Query:         "query Pet($id: ID!) { pet(id: $id) { name } }",
+						Variables:     map[string]interface{}{"id": 1},
+						OperationName: "Pet",
+					},
+				},
+			},
+			expected: model.Request{
+				Url:    "http://foobar.com/graphql",
+				Method: "POST",
+				Headers: model.Headers{
+					"Content-Type": {"application/json"},
+				},
+				Body:    `{"query":"query Pet($id: ID!) { pet(id: $id) { name } }","variables":{"id":1},"operationName":"Pet"}`,
+				Options: make(map[string]interface{}),
+			},
+		},
+		{
+			name: "Test with graphql request using GET",
+			mold: model.RequestMold{
+				Yaml: &model.YamlRequest{
+					Url:    "http://foobar.com/graphql",
+					Method: "GET",
+					GraphQL: &model.GraphQLRequest{
+						Query:     "{ pets { name } }",
+						Variables: map[string]interface{}{"first": 2},
+					},
+				},
+			},
+			expected: model.Request{
+				Url:     "http://foobar.com/graphql?query=%7B+pets+%7B+name+%7D+%7D&variables=%7B%22first%22%3A2%7D",
+				Method:  "GET",
+				Options: make(map[string]interface{}),
+			},
+		},
+		{
+			name: "Test with templated graphql document",
+			mold: model.RequestMold{
+				Type:     model.CONTENT_TYPE_GRAPHQL,
+				Filename: "pets.graphql",
+				Yaml: &model.YamlRequest{
+					Url: "{domain}/graphql",
+					Raw: `# ---
+# url: "{domain}/graphql"
+# headers:
+#   Content-Type: application/graphql+json
+#   Authorization: Bearer {token}
+# ---
+# pets of the first page
+{ pets(first: 2) { name } }`,
+				},
+			},
+			profile: model.Profile{
+				Name: "test",
+				Variables: map[string]string{
+					"domain": "http://localhost:8080",
+					"token":  "abc",
+				},
+			},
+			expected: model.Request{
+				Url:    "http://localhost:8080/graphql",
+				Method: "POST",
+				Headers: model.Headers{
+					"Content-Type":  {"application/graphql+json"},
+					"Authorization": {"Bearer abc"},
+				},
+				Body:    `{"query":"# pets of the first page\n{ pets(first: 2) { name } }"}`,
+				Options: make(map[string]interface{}),
+			},
+		},
+		{
+			name: "Test with grpc request resolving proto paths",
+			mold: model.RequestMold{
+				Name:     "hello",
+				Filename: "hello.yaml",
+				Root:     "/requests",
+				Yaml: &model.YamlRequest{
+					Url:  "grpc://{domain}",
+					Grpc: &model.GrpcRequest{Service: "helloworld.Greeter", Method: "SayHello"},
+					Raw: `url: grpc://{domain}
+grpc:
+  service: helloworld.Greeter
+  method: SayHello
+  proto_files:
+    - protos/hello.proto
+  import_paths:
+    - /usr/include
+body: >
+  {"name": "jane"}`,
+				},
+			},
+			profile: model.Profile{
+				Name: "test",
+				Variables: map[string]string{
+					"domain": "localhost:50051",
+				},
+			},
+			expected: model.Request{
+				Url:      "grpc://localhost:50051",
+				Body:     "{\"name\": \"jane\"}",
+				Options:  make(map[string]interface{}),
+				Protocol: model.PROTOCOL_GRPC,
+				Grpc: &model.GrpcRequest{
+					Service:     "helloworld.Greeter",
+					Method:      "SayHello",
+					ProtoFiles:  []string{"/requests/protos/hello.proto"},
+					ImportPaths: []string{"/usr/include"},
+				},
+				Root: "/requests",
+			},
+		},
+
+		{
+			name: "Test with query and path params",
+			mold: model.RequestMold{
+				Name: "yaml_request",
+				Yaml: &model.YamlRequest{
+					Url: "{domain}/pets/{id}",
+					Raw: `url: "{domain}/pets/{id}/{owner}?fields=name#top"
+method: GET
+path_params:
+  id: 42
+  owner: "jane doe/2"
+query:
+  tags:
+    - a&b
+    - c
+  q: "x y"
+  page: 1`,
+				},
+			},
+			profile: model.Profile{
+				Name: "test",
+				Variables: map[string]string{
+					"domain": "http://localhost:8080",
+				},
+			},
+			expected: model.Request{
+				Url:     "http://localhost:8080/pets/42/jane%20doe%2F2?fields=name&page=1&q=x+y&tags=a%26b&tags=c#top",
+				Method:  "GET",
+				Options: make(map[string]interface{}),
+				Query: model.QueryParams{
+					"tags": {"a&b", "c"},
+					"q":    {"x y"},
+					"page": {"1"},
+				},
+				PathParams: map[string]string{
+					"id":    "42",
+					"owner": "jane doe/2",
+				},
+			},
+		},
+
+		{
+			name: "Test with path params outside of path",
+			mold: model.RequestMold{
+				Name: "yaml_request",
+				Yaml: &model.YamlRequest{
+					Url: "{domain}/pets/{id}",
+					Raw: `url: "{domain}/pets/{id}?filter={id}#{id}"
+method: GET
+path_params:
+  id: 42`,
+				},
+			},
+			profile: model.Profile{
+				Name: "test",
+				Variables: map[string]string{
+					"domain": "http://localhost:8080",
+				},
+			},
+			expected: model.Request{
+				Url:     "http://localhost:8080/pets/42?filter={id}#{id}",
+				Method:  "GET",
+				Options: make(map[string]interface{}),
+				PathParams: map[string]string{
+					"id": "42",
+				},
+			},
+		},
+
+		{
+			name: "Test with body file",
+			mold: model.RequestMold{
+				Name: "yaml_request",
+				Root: "testdata",
+				Yaml: &model.YamlRequest{
+					Url:      "http://foobar.com",
+					Method:   "POST",
+					BodyFile: "payloads/pet.json",
+				},
+			},
+			profile: model.Profile{},
+			expected: model.Request{
+				Url:     "http://foobar.com",
+				Method:  "POST",
+				Body:    "{\"name\": \"{name}\", \"id\": 1}\n",
+				Options: make(map[string]interface{}),
+				Root:    "testdata",
+			},
+		},
+		{
+			name: "Test with templated body file",
+			mold: model.RequestMold{
+				Name: "yaml_request",
+				Root: "testdata",
+				Yaml: &model.YamlRequest{
+					Url: "{domain}",
+					Raw: `url: "{domain}"
+method: POST
+body_file: payloads/pet.json
+body_file_template: true`,
+				},
+			},
+			profile: model.Profile{
+				Name: "test",
+				Variables: map[string]string{
+					"domain": "http://localhost:8080",
+					"name":   "Rex",
+				},
+			},
+			expected: model.Request{
+				Url:     "http://localhost:8080",
+				Method:  "POST",
+				Body:    "{\"name\": \"Rex\", \"id\": 1}\n",
+				Options: make(map[string]interface{}),
+				Root:    "testdata",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -226,6 +579,27 @@ body:
 
 }
 
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		url        string
+		beforePath string
+		path       string
+		afterPath  string
+	}{
+		{"http://user@{id}.com:8080/pets/{id}?q={id}#{id}", "http://user@{id}.com:8080", "/pets/{id}", "?q={id}#{id}"},
+		{"http://localhost:8080/pets/{id}?q={id}#{id}", "http://localhost:8080", "/pets/{id}", "?q={id}#{id}"},
+		{"http://localhost:8080?q={id}", "http://localhost:8080", "", "?q={id}"},
+		{"http://localhost:8080", "http://localhost:8080", "", ""},
+		{"{domain}/pets/{id}#top", "", "{domain}/pets/{id}", "#top"},
+	}
+	for _, tt := range tests {
+		beforePath, path, afterPath := splitPath(tt.url)
+		assert.Equal(t, tt.beforePath, beforePath, tt.url)
+		assert.Equal(t, tt.path, path, tt.url)
+		assert.Equal(t, tt.afterPath, afterPath, tt.url)
+	}
+}
+
 func TestBuildStarlarkRequests(t *testing.T) {
 
 	tests := []struct {
@@ -408,6 +782,124 @@ body = {
 				Options: make(map[string]interface{}),
 			},
 		},
+		{
+			name: "Test with oauth2 authentication",
+			mold: model.RequestMold{
+				Name: "Starlark request",
+				Type: "star",
+				Scriptable: &model.ScriptableRequest{
+					Script: `
+url = "http://foobar.com"
+method = "GET"
+auth = {
+    "oauth2": {
+        "grant_type": "client_credentials",
+        "token_url": "http://foobar.com/token",
+        "client_id": "my-client",
+        "client_secret": "secret",
+        "scopes": ["read", "write"]
+    }
+}`,
+				},
+			},
+			profile: model.Profile{},
+			expected: model.Request{
+				Url:     "http://foobar.com",
+				Method:  "GET",
+				Headers: model.Headers{},
+				Options: make(map[string]interface{}),
+				OAuth2: &model.OAuth2Auth{
+					GrantType:    "client_credentials",
+					TokenUrl:     "http://foobar.com/token",
+					ClientId:     "my-client",
+					ClientSecret: "secret",
+					Scopes:       []string{"read", "write"},
+				},
+			},
+		},
+		{
+			name: "Test with api key in header",
+			mold: model.RequestMold{
+				Name: "Starlark request",
+				Type: "star",
+				Scriptable: &model.ScriptableRequest{
+					Script: `
+url = "http://foobar.com"
+method = "GET"
+auth = {
+    "api_key": {
+        "name": "X-Api-Key",
+        "value": "secret",
+        "in": "header"
+    }
+}`,
+				},
+			},
+			profile: model.Profile{},
+			expected: model.Request{
+				Url:    "http://foobar.com",
+				Method: "GET",
+				Headers: model.Headers{
+					"X-Api-Key": {"secret"},
+				},
+				Options: make(map[string]interface{}),
+			},
+		},
+
+		{
+			name: "Test with query and path params",
+			mold: model.RequestMold{
+				Name: "Starlark request",
+				Type: "star",
+				Scriptable: &model.ScriptableRequest{
+					Script: `
+url = "http://foobar.com/pets/{id}"
+method = "GET"
+path_params = { "id": 42 }
+query = { "tags": [ "a", "b" ], "limit": 10 }
+`,
+				},
+			},
+			profile: model.Profile{},
+			expected: model.Request{
+				Url:     "http://foobar.com/pets/42?limit=10&tags=a&tags=b",
+				Method:  "GET",
+				Headers: model.Headers{},
+				Options: make(map[string]interface{}),
+				Query: model.QueryParams{
+					"tags":  {"a", "b"},
+					"limit": {"10"},
+				},
+				PathParams: map[string]string{
+					"id": "42",
+				},
+			},
+		},
+
+		{
+			name: "Test with body from file",
+			mold: model.RequestMold{
+				Name: "Starlark request",
+				Type: "star",
+				Root: "testdata",
+				Scriptable: &model.ScriptableRequest{
+					Script: `
+url = "http://foobar.com"
+method = "POST"
+body = file("payloads/pet.json")
+`,
+				},
+			},
+			profile: model.Profile{},
+			expected: model.Request{
+				Url:     "http://foobar.com",
+				Method:  "POST",
+				Headers: model.Headers{},
+				Body:    "{\"name\": \"{name}\", \"id\": 1}\n",
+				Options: make(map[string]interface{}),
+				Root:    "testdata",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -610,6 +1102,102 @@ return {
 				Options: make(map[string]interface{}),
 			},
 		},
+		{
+			name: "Test with oauth2 authentication",
+			mold: model.RequestMold{
+				Name: "Lua request",
+				Type: "lua",
+				Scriptable: &model.ScriptableRequest{
+					Script: `
+return {
+	url = "http://foobar.com",
+	method = "GET",
+	auth = {
+		oauth2 = {
+			grant_type = "password",
+			token_url = "http://foobar.com/token",
+			client_id = "my-client",
+			username = "jane",
+			password = "doe",
+			scopes = { "read" }
+		}
+	}
+}`,
+				},
+			},
+			profile: model.Profile{},
+			expected: model.Request{
+				Url:     "http://foobar.com",
+				Method:  "GET",
+				Headers: model.Headers{},
+				Options: make(map[string]interface{}),
+				OAuth2: &model.OAuth2Auth{
+					GrantType: "password",
+					TokenUrl:  "http://foobar.com/token",
+					ClientId:  "my-client",
+					Username:  "jane",
+					Password:  "doe",
+					Scopes:    []string{"read"},
+				},
+			},
+		},
+
+		{
+			name: "Test with query and path params",
+			mold: model.RequestMold{
+				Name: "Lua request",
+				Type: "lua",
+				Scriptable: &model.ScriptableRequest{
+					Script: `
+return {
+	url = "http://foobar.com/pets/{id}",
+	method = "GET",
+	path_params = { id = 42 },
+	query = { tags = { "a", "b" }, q = "x y" }
+}`,
+				},
+			},
+			profile: model.Profile{},
+			expected: model.Request{
+				Url:     "http://foobar.com/pets/42?q=x+y&tags=a&tags=b",
+				Method:  "GET",
+				Headers: model.Headers{},
+				Options: make(map[string]interface{}),
+				Query: model.QueryParams{
+					"tags": {"a", "b"},
+					"q":    {"x y"},
+				},
+				PathParams: map[string]string{
+					"id": "42",
+				},
+			},
+		},
+
+		{
+			name: "Test with body from file",
+			mold: model.RequestMold{
+				Name: "Lua request",
+				Type: "lua",
+				Root: "testdata",
+				Scriptable: &model.ScriptableRequest{
+					Script: `
+return {
+	url = "http://foobar.com",
+	method = "POST",
+	body = file("payloads/pet.json")
+}`,
+				},
+			},
+			profile: model.Profile{},
+			expected: model.Request{
+				Url:     "http://foobar.com",
+				Method:  "POST",
+				Headers: model.Headers{},
+				Body:    "{\"name\": \"{name}\", \"id\": 1}\n",
+				Options: make(map[string]interface{}),
+				Root:    "testdata",
+			},
+		},
 	}
 
 	for _, tt := range tests {
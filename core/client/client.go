@@ -1,11 +1,14 @@
 package client
 
 import (
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"github.com/susiteemu/startpoint/core/configuration"
 	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/tools/urls"
+	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -39,11 +42,23 @@ func DoRequest(request model.Request) (*model.Response, error) {
 	requestHeaders := request.Headers.ToMap()
 	log.Debug().Msgf("Request %v -- %v -- %v -- %v -- %v", request.Url, request.Body, request.Method, request.Headers, request.Options)
 
+	config := configuration.NewWithRequestOptions(request.Options)
+
 	// NOTE: creating new client for each request to simplify configuring it
-	// (no need to reset to default values after request)
-	var client = resty.New().SetLogger(newLogger(&log.Logger))
+	// (no need to reset to default values after request). Connections live in pooled
+	// transports shared by clients with the same connection configuration.
+	requestUrl := request.Url
+	unixSocket, _ := config.GetString("httpClient.unixSocket")
+	if socketPath, requestPath, ok := urls.SplitUnixSocketUrl(request.Url); ok {
+		unixSocket = socketPath
+		requestUrl = urls.ToHttpUrl(requestPath)
+	}
 
-	config := configuration.NewWithRequestOptions(request.Options)
+	transport, err := transportFor(config, unixSocket)
+	if err != nil {
+		return nil, err
+	}
+	var client = resty.New().SetLogger(newLogger(&log.Logger)).SetTransport(transport)
 
 	debug := config.GetBool("debug")
 	if debug {
@@ -58,47 +73,35 @@ func DoRequest(request model.Request) (*model.Response, error) {
 		client.SetTimeout(time.Duration(timeoutSeconds * int(time.Second)))
 	}
 
-	proxy, set := config.GetString("httpClient.proxyUrl")
-	if set && len(proxy) > 0 {
-		client = client.SetProxy(proxy)
+	if request.CookieJar != nil {
+		client.SetCookieJar(request.CookieJar)
 	}
 
-	insecure := config.GetBoolWithDefault("httpClient.insecure", false)
-	if insecure {
-		client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
-	} else {
-		rootCertificates, _ := config.GetStringSlice("httpClient.rootCertificates")
-		if len(rootCertificates) > 0 {
-			for _, cert := range rootCertificates {
-				client.SetRootCertificate(cert)
-			}
-		}
-		clientCertificates, set := config.GetSliceMapString("httpClient.clientCertificates")
-		if set && len(clientCertificates) > 0 {
-			var certs []tls.Certificate
-			for _, pair := range clientCertificates {
-				// NOTE: viper makes all keys lowercase which is a bit annoying because
-				// configuration coming from request does not have lowercase keys: we now must handle both cases
-				certFile, found := pair["certfile"]
-				if !found {
-					certFile = pair["certFile"]
-				}
-				keyFile, found := pair["keyfile"]
-				if !found {
-					keyFile = pair["keyFile"]
-				}
-				cert, err := tls.LoadX509KeyPair(certFile, keyFile)
-				if err != nil {
-					log.Error().Err(err).Msg("Error with client certificate")
-					return nil, err
-				}
-				certs = append(certs, cert)
-			}
-			client.SetCertificates(certs...)
+	attempts := configureRetry(client, config)
+	redirects := configureRedirects(client, config)
+
+	r := client.R().SetHeaders(requestHeaders)
+
+	if request.OAuth2 != nil {
+		authorization, err := oauth2Authorization(*request.OAuth2, request.Root, config)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to get oauth2 token")
+			return nil, err
 		}
+		r.SetHeader(model.HEADER_NAME_AUTHORIZATION, authorization)
 	}
 
-	r := client.R().SetHeaders(requestHeaders)
+	if request.Digest != nil {
+		r.SetDigestAuth(request.Digest.User, request.Digest.Password)
+	}
+
+	if request.AwsSigV4 != nil {
+		// signed right before sending so that the signature covers the final request
+		auth := *request.AwsSigV4
+		client.SetPreRequestHook(func(c *resty.Client, req *http.Request) error {
+			return signAwsSigV4(req, auth, time.Now())
+		})
+	}
 
 	enableTrace := config.GetBool("httpClient.enableTraceInfo")
 	if enableTrace {
@@ -112,25 +115,11 @@ func DoRequest(request model.Request) (*model.Response, error) {
 		}
 		r.SetFormData(bodyAsMap)
 	} else if request.IsMultipartForm() {
-		bodyAsMap, ok := request.BodyAsMap()
-		if !ok {
-			return nil, errors.New("cannot convert body to map")
-		}
-		formData := make(map[string]string)
-		files := make(map[string]string)
-		for k, v := range bodyAsMap {
-			if strings.HasPrefix(v, "@") {
-				files[k] = strings.TrimPrefix(v, "@")
-			} else {
-				formData[k] = v
-			}
-		}
-		if len(formData) > 0 {
-			r.SetFormData(formData)
-		}
-		if len(files) > 0 {
-			r.SetFiles(files)
+		fields, err := multipartFields(request)
+		if err != nil {
+			return nil, err
 		}
+		r.SetMultipartFields(fields...)
 	} else {
 		r.SetBody(request.Body)
 	}
@@ -139,11 +128,42 @@ func DoRequest(request model.Request) (*model.Response, error) {
 		r.SetOutput(request.Output)
 	}
 
-	resp, err := r.Execute(request.Method, request.Url)
+	maxBodyBytes, set := config.GetInt("printer.maxBodyBytes")
+	if !set {
+		maxBodyBytes = defaultMaxBodyBytes
+	}
+	streaming := len(request.Output) == 0 && (request.OnEvent != nil || maxBodyBytes > 0)
+	if streaming {
+		// body is read by us so that events can be handled while they arrive and large
+		// bodies can be written to disk instead of memory
+		r.SetDoNotParseResponse(true)
+		client.AddRetryHook(func(resp *resty.Response, err error) {
+			if resp != nil && resp.RawResponse != nil {
+				resp.RawBody().Close()
+			}
+		})
+	}
+
+	resp, err := r.Execute(request.Method, requestUrl)
 	if err != nil {
 		return nil, err
 	}
 
+	var streamedBody *rawBody
+	var events []model.Event
+	// reading a streamed body is not included in the time measured by resty
+	var bodyTime time.Duration
+	if streaming {
+		streamedBody, events, err = readStreamedBody(r, resp, request.Method, requestUrl, request.OnEvent, int64(maxBodyBytes), config)
+		if err != nil {
+			return nil, err
+		}
+		bodyTime = time.Since(resp.ReceivedAt())
+		if r.Debug {
+			logStreamedBody(streamedBody, resp.Time()+bodyTime)
+		}
+	}
+
 	ti := resp.Request.TraceInfo()
 	traceInfo := model.TraceInfo{}
 	if enableTrace {
@@ -153,8 +173,8 @@ func DoRequest(request model.Request) (*model.Response, error) {
 			TCPConnTime:    ti.TCPConnTime,
 			TLSHandshake:   ti.TLSHandshake,
 			ServerTime:     ti.ServerTime,
-			ResponseTime:   ti.ResponseTime,
-			TotalTime:      ti.TotalTime,
+			ResponseTime:   ti.ResponseTime + bodyTime,
+			TotalTime:      ti.TotalTime + bodyTime,
 			IsConnReused:   ti.IsConnReused,
 			IsConnWasIdle:  ti.IsConnWasIdle,
 			ConnIdleTime:   ti.ConnIdleTime,
@@ -162,10 +182,23 @@ func DoRequest(request model.Request) (*model.Response, error) {
 			RemoteAddr:     ti.RemoteAddr.String(),
 		}
 	}
+	if len(*attempts) > 0 {
+		if len(*attempts) < resp.Request.Attempt {
+			*attempts = append(*attempts, toAttempt(resp, nil))
+		}
+		traceInfo.RequestAttempt = resp.Request.Attempt
+		traceInfo.Attempts = *attempts
+	}
 
 	var body []byte
+	var bodyFile string
+	size := resp.Size()
 	if resp.IsSuccess() && len(request.Output) > 0 {
 		body = []byte(fmt.Sprintf("Saved to file %s", request.Output))
+	} else if streaming {
+		body = streamedBody.bytes
+		bodyFile = streamedBody.file
+		size = streamedBody.size
 	} else {
 		body = resp.Body()
 	}
@@ -181,8 +214,14 @@ func DoRequest(request model.Request) (*model.Response, error) {
 		requestBody = bodyAsMap
 	}
 
+	respUrl := req.URL
+	if requestUrl != request.Url {
+		// show the url as it was given instead of the one sent over unix socket
+		respUrl = request.Url
+	}
+
 	respReq := model.Request{
-		Url:     req.URL,
+		Url:     respUrl,
 		Method:  req.Method,
 		Body:    requestBody,
 		Headers: new(model.Headers).FromMap(req.Header),
@@ -191,18 +230,163 @@ func DoRequest(request model.Request) (*model.Response, error) {
 	response := model.Response{
 		Headers:    new(model.Headers).FromMap(resp.Header()),
 		Body:       body,
+		BodyFile:   bodyFile,
 		Status:     resp.Status(),
 		StatusCode: resp.StatusCode(),
 		Proto:      resp.Proto(),
-		Size:       resp.Size(),
-		ReceivedAt: resp.ReceivedAt(),
-		Time:       resp.Time(),
+		Size:       size,
+		ReceivedAt: resp.ReceivedAt().Add(bodyTime),
+		Time:       resp.Time() + bodyTime,
 		TraceInfo:  traceInfo,
 		Options:    request.Options,
 		Request:    respReq,
+		Redirects:  *redirects,
+		Events:     events,
 	}
 
 	log.Debug().Msgf("TraceInfo: %v", traceInfo)
 
 	return &response, nil
 }
+
+var defaultRetryOnStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+const defaultMaxRedirects = 10
+
+// defaultMaxBodyBytes is the size after which response bodies are written to a temporary file
+const defaultMaxBodyBytes = 1024 * 1024
+
+// configureRetry sets retry policy to client from configuration. Returns a pointer to attempts
+// which is filled during the request if retries are enabled.
+func configureRetry(client *resty.Client, config *configuration.Configuration) *[]model.Attempt {
+	attempts := []model.Attempt{}
+
+	retryCount, set := config.GetInt("httpClient.retry.count")
+	if !set || retryCount <= 0 {
+		return &attempts
+	}
+	client.SetRetryCount(retryCount)
+
+	waitSeconds, set := config.GetInt("httpClient.retry.waitSeconds")
+	if set && waitSeconds >= 0 {
+		client.SetRetryWaitTime(time.Duration(waitSeconds) * time.Second)
+	}
+	maxWaitSeconds, set := config.GetInt("httpClient.retry.maxWaitSeconds")
+	if set && maxWaitSeconds >= 0 {
+		client.SetRetryMaxWaitTime(time.Duration(maxWaitSeconds) * time.Second)
+	}
+
+	onStatus, set := config.GetIntSlice("httpClient.retry.onStatus")
+	if !set || len(onStatus) == 0 {
+		onStatus = defaultRetryOnStatus
+	}
+	client.AddRetryCondition(func(resp *resty.Response, err error) bool {
+		if err != nil {
+			return true
+		}
+		return resp != nil && slices.Contains(onStatus, resp.StatusCode())
+	})
+
+	if config.GetBoolWithDefault("httpClient.retry.honorRetryAfter", false) {
+		client.SetRetryAfter(func(c *resty.Client, resp *resty.Response) (time.Duration, error) {
+			return parseRetryAfter(resp.Header().Get("Retry-After"), time.Now()), nil
+		})
+	}
+
+	client.AddRetryHook(func(resp *resty.Response, err error) {
+		attempt := toAttempt(resp, err)
+		if attempt.Attempt == 0 {
+			attempt.Attempt = len(attempts) + 1
+		}
+		attempts = append(attempts, attempt)
+		log.Info().Msgf("Attempt %d failed with status %d, error %v", attempt.Attempt, attempt.StatusCode, err)
+	})
+	return &attempts
+}
+
+// configureRedirects sets redirect policy to client from configuration. Returns a pointer to redirects
+// which is filled with the hops of the last attempt during the request.
+func configureRedirects(client *resty.Client, config *configuration.Configuration) *[]model.Redirect {
+	redirects := []model.Redirect{}
+	hopStart := time.Now()
+
+	client.OnBeforeRequest(func(c *resty.Client, r *resty.Request) error {
+		// new attempt: forget hops of the previous one
+		redirects = redirects[:0]
+		hopStart = time.Now()
+		return nil
+	})
+
+	followRedirects := config.GetBoolWithDefault("httpClient.followRedirects", true)
+	maxRedirects, set := config.GetInt("httpClient.maxRedirects")
+	if !set || maxRedirects < 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+
+	client.SetRedirectPolicy(resty.RedirectPolicyFunc(func(req *http.Request, via []*http.Request) error {
+		if !followRedirects {
+			// return the redirect response itself
+			return http.ErrUseLastResponse
+		}
+		if len(via) > maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		redirect := model.Redirect{
+			Url:  via[len(via)-1].URL.String(),
+			Time: time.Since(hopStart),
+		}
+		if req.Response != nil {
+			redirect.StatusCode = req.Response.StatusCode
+			redirect.Status = req.Response.Status
+			redirect.Location = req.Response.Header.Get("Location")
+		}
+		redirects = append(redirects, redirect)
+		hopStart = time.Now()
+		log.Debug().Msgf("Following redirect %s -> %s", redirect.Status, redirect.Location)
+		return nil
+	}))
+	return &redirects
+}
+
+// parseRetryAfter parses Retry-After header given either as seconds or as a http date.
+// Returns zero if the header is missing or invalid.
+func parseRetryAfter(retryAfter string, now time.Time) time.Duration {
+	retryAfter = strings.TrimSpace(retryAfter)
+	if len(retryAfter) == 0 {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(retryAfter); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(retryAfter); err == nil {
+		wait := at.Sub(now)
+		if wait < 0 {
+			return 0
+		}
+		return wait
+	}
+	log.Warn().Msgf("Could not parse Retry-After header value %s", retryAfter)
+	return 0
+}
+
+func toAttempt(resp *resty.Response, err error) model.Attempt {
+	attempt := model.Attempt{}
+	if resp != nil {
+		attempt.Attempt = resp.Request.Attempt
+		attempt.StatusCode = resp.StatusCode()
+		attempt.Status = resp.Status()
+		attempt.Time = resp.Time()
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	return attempt
+}
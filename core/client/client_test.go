@@ -0,0 +1,548 @@
+package client
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/configuration"
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoRequestWithRetry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name             string
+		options          map[string]interface{}
+		expectedStatus   int
+		expectedAttempts []int
+	}{
+		{
+			name:             "Without retry",
+			options:          map[string]interface{}{},
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedAttempts: nil,
+		},
+		{
+			name: "Retry until success",
+			options: map[string]interface{}{
+				"httpClient.retry.count":           3,
+				"httpClient.retry.waitSeconds":     0,
+				"httpClient.retry.maxWaitSeconds":  0,
+				"httpClient.retry.honorRetryAfter": true,
+			},
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name: "Retry only on given statuses",
+			options: map[string]interface{}{
+				"httpClient.retry.count":          3,
+				"httpClient.retry.waitSeconds":    0,
+				"httpClient.retry.maxWaitSeconds": 0,
+				"httpClient.retry.onStatus":       []interface{}{503},
+			},
+			expectedStatus:   http.StatusTooManyRequests,
+			expectedAttempts: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			resp, err := DoRequest(model.Request{
+				Url:     server.URL,
+				Method:  http.MethodGet,
+				Headers: model.Headers{},
+				Options: tt.options,
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			var statuses []int
+			for i, a := range resp.TraceInfo.Attempts {
+				assert.Equal(t, i+1, a.Attempt)
+				statuses = append(statuses, a.StatusCode)
+			}
+			assert.Equal(t, tt.expectedAttempts, statuses)
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter("Mon, 01 Jan 2024 12:00:30 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Mon, 01 Jan 2024 11:00:00 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+}
+
+func TestDoRequestWithRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.Redirect(w, r, "/sso", http.StatusFound)
+		case "/sso":
+			http.Redirect(w, r, "/home", http.StatusSeeOther)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name              string
+		options           map[string]interface{}
+		expectedStatus    int
+		expectedLocations []string
+		expectErr         bool
+	}{
+		{
+			name:              "Follow redirects",
+			options:           map[string]interface{}{},
+			expectedStatus:    http.StatusOK,
+			expectedLocations: []string{"/sso", "/home"},
+		},
+		{
+			name:              "Do not follow redirects",
+			options:           map[string]interface{}{"httpClient.followRedirects": false},
+			expectedStatus:    http.StatusFound,
+			expectedLocations: nil,
+		},
+		{
+			name:      "Too many redirects",
+			options:   map[string]interface{}{"httpClient.maxRedirects": 1},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := DoRequest(model.Request{
+				Url:     server.URL + "/login",
+				Method:  http.MethodGet,
+				Headers: model.Headers{},
+				Options: tt.options,
+			})
+			if tt.expectErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			var locations []string
+			for _, r := range resp.Redirects {
+				locations = append(locations, r.Location)
+			}
+			assert.Equal(t, tt.expectedLocations, locations)
+		})
+	}
+}
+
+func TestDoRequestReusesConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	request := model.Request{
+		Url:     server.URL,
+		Method:  http.MethodGet,
+		Headers: model.Headers{},
+		Options: map[string]interface{}{"httpClient.enableTraceInfo": true},
+	}
+	first, err := DoRequest(request)
+	assert.Nil(t, err)
+	assert.False(t, first.TraceInfo.IsConnReused)
+
+	second, err := DoRequest(request)
+	assert.Nil(t, err)
+	assert.True(t, second.TraceInfo.IsConnReused)
+}
+
+func TestTransportFor(t *testing.T) {
+	withOptions := func(options map[string]interface{}) *http.Transport {
+		transport, err := transportFor(configuration.NewWithRequestOptions(options), "")
+		assert.Nil(t, err)
+		return transport
+	}
+
+	plain := withOptions(map[string]interface{}{})
+	assert.Same(t, plain, withOptions(map[string]interface{}{"httpClient.debug": true}))
+	assert.NotSame(t, plain, withOptions(map[string]interface{}{"httpClient.timeoutSeconds": 5}))
+	assert.NotSame(t, plain, withOptions(map[string]interface{}{"httpClient.proxyUrl": "http://localhost:3128"}))
+
+	insecure := withOptions(map[string]interface{}{"httpClient.insecure": true})
+	assert.NotSame(t, plain, insecure)
+	assert.True(t, insecure.TLSClientConfig.InsecureSkipVerify)
+
+	_, err := transportFor(configuration.NewWithRequestOptions(map[string]interface{}{
+		"httpClient.clientCertificates": []interface{}{
+			map[string]interface{}{"certFile": "missing.crt", "keyFile": "missing.key"},
+		},
+	}), "")
+	assert.NotNil(t, err)
+}
+
+func TestDoRequestOverUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "app.sock")
+	listener, err := net.Listen("unix", socket)
+	assert.Nil(t, err)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		url          string
+		options      map[string]interface{}
+		expectedBody string
+	}{
+		{
+			name:         "Socket in url",
+			url:          "unix://" + socket + ":/v1.43/containers/json",
+			options:      map[string]interface{}{},
+			expectedBody: "/v1.43/containers/json",
+		},
+		{
+			name:         "Socket in options",
+			url:          "http://docker/v1.43/info",
+			options:      map[string]interface{}{"httpClient.unixSocket": socket},
+			expectedBody: "/v1.43/info",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := DoRequest(model.Request{
+				Url:     tt.url,
+				Method:  http.MethodGet,
+				Headers: model.Headers{},
+				Options: tt.options,
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedBody, string(resp.Body))
+			assert.Equal(t, tt.url, resp.Request.Url)
+		})
+	}
+}
+
+func TestDoRequestWithDigestAuth(t *testing.T) {
+	const realm, nonce = "cameras", "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := map[string]string{}
+		authorization, found := strings.CutPrefix(r.Header.Get("Authorization"), "Digest ")
+		if found {
+			for _, part := range strings.Split(authorization, ",") {
+				k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+				params[k] = strings.Trim(v, `"`)
+			}
+		}
+		md5Hex := func(s string) string {
+			return fmt.Sprintf("%x", md5.Sum([]byte(s)))
+		}
+		ha1 := md5Hex("jane:" + realm + ":doe")
+		ha2 := md5Hex(r.Method + ":" + params["uri"])
+		expected := md5Hex(strings.Join([]string{ha1, nonce, params["nc"], params["cnonce"], "auth", ha2}, ":"))
+		if params["username"] != "jane" || params["response"] != expected {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="%s", nonce="%s", qop="auth", algorithm=MD5`, realm, nonce))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		password       string
+		expectedStatus int
+	}{
+		{"Correct password", "doe", http.StatusOK},
+		{"Wrong password", "foo", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := DoRequest(model.Request{
+				Url:     server.URL + "/snapshot",
+				Method:  http.MethodGet,
+				Headers: model.Headers{},
+				Digest:  &model.DigestAuth{User: "jane", Password: tt.password},
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestDoRequestWithEventStream(t *testing.T) {
+	var connections atomic.Int32
+	var lastEventIds []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain" {
+			w.Write([]byte("not a stream"))
+			return
+		}
+		lastEventIds = append(lastEventIds, r.Header.Get("Last-Event-ID"))
+		if connections.Add(1) > 2 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		fmt.Fprintf(w, "retry: 10\nid: %d\ndata: event %d\n\n", connections.Load(), connections.Load())
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		path            string
+		options         map[string]interface{}
+		expectedEvents  []string
+		expectedLastIds []string
+		expectedBody    string
+	}{
+		{
+			name:            "Single stream",
+			path:            "/events",
+			options:         map[string]interface{}{},
+			expectedEvents:  []string{"event 1"},
+			expectedLastIds: []string{""},
+			expectedBody:    "retry: 10\nid: 1\ndata: event 1\n\n",
+		},
+		{
+			name:            "Reconnect until no content",
+			path:            "/events",
+			options:         map[string]interface{}{"sse.reconnect": true},
+			expectedEvents:  []string{"event 1", "event 2"},
+			expectedLastIds: []string{"", "1", "2"},
+			expectedBody:    "retry: 10\nid: 1\ndata: event 1\n\nretry: 10\nid: 2\ndata: event 2\n\n",
+		},
+		{
+			name:         "Not an event stream",
+			path:         "/plain",
+			options:      map[string]interface{}{},
+			expectedBody: "not a stream",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connections.Store(0)
+			lastEventIds = nil
+			var received []string
+			resp, err := DoRequest(model.Request{
+				Url:     server.URL + tt.path,
+				Method:  http.MethodGet,
+				Headers: model.Headers{},
+				Options: tt.options,
+				OnEvent: func(event model.Event) {
+					received = append(received, event.Data)
+				},
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedEvents, received)
+			assert.Equal(t, len(tt.expectedEvents), len(resp.Events))
+			assert.Equal(t, tt.expectedLastIds, lastEventIds)
+			assert.Equal(t, tt.expectedBody, string(resp.Body))
+			assert.Equal(t, int64(len(tt.expectedBody)), resp.Size)
+		})
+	}
+}
+
+func TestDoRequestWithLargeBody(t *testing.T) {
+	body := strings.Repeat("0123456789", 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		options      map[string]interface{}
+		expectedBody string
+		expectFile   bool
+	}{
+		{
+			name:         "Body under the limit",
+			options:      map[string]interface{}{"printer.maxBodyBytes": 100},
+			expectedBody: body,
+		},
+		{
+			name:         "Body over the limit",
+			options:      map[string]interface{}{"printer.maxBodyBytes": 25},
+			expectedBody: body[:25],
+			expectFile:   true,
+		},
+		{
+			name:         "Limit disabled",
+			options:      map[string]interface{}{"printer.maxBodyBytes": 0},
+			expectedBody: body,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := DoRequest(model.Request{
+				Url:     server.URL,
+				Method:  http.MethodGet,
+				Headers: model.Headers{},
+				Options: tt.options,
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedBody, string(resp.Body))
+			assert.Equal(t, int64(len(body)), resp.Size)
+			if !tt.expectFile {
+				assert.Empty(t, resp.BodyFile)
+				return
+			}
+			defer RemoveBodyFile(resp.BodyFile)
+			assert.Equal(t, ".json", filepath.Ext(resp.BodyFile))
+			written, err := os.ReadFile(resp.BodyFile)
+			assert.Nil(t, err)
+			assert.Equal(t, body, string(written))
+			fullBody, err := resp.FullBody()
+			assert.Nil(t, err)
+			assert.Equal(t, body, string(fullBody))
+		})
+	}
+}
+
+func TestDoRequestRemovesBodyFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	defer server.Close()
+
+	resp, err := DoRequest(model.Request{
+		Url:     server.URL,
+		Method:  http.MethodGet,
+		Headers: model.Headers{},
+		Options: map[string]interface{}{"printer.maxBodyBytes": 10},
+	})
+	assert.Nil(t, err)
+	assert.FileExists(t, resp.BodyFile)
+
+	other, err := DoRequest(model.Request{
+		Url:     server.URL,
+		Method:  http.MethodGet,
+		Headers: model.Headers{},
+		Options: map[string]interface{}{"printer.maxBodyBytes": 10},
+	})
+	assert.Nil(t, err)
+
+	RemoveBodyFile(resp.BodyFile)
+	assert.NoFileExists(t, resp.BodyFile)
+	assert.FileExists(t, other.BodyFile)
+
+	RemoveAllBodyFiles()
+	assert.NoFileExists(t, other.BodyFile)
+}
+
+func TestDoRequestTimeIncludesReadingBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("first"))
+		w.(http.Flusher).Flush()
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("second"))
+	}))
+	defer server.Close()
+
+	resp, err := DoRequest(model.Request{
+		Url:     server.URL,
+		Method:  http.MethodGet,
+		Headers: model.Headers{},
+		Options: map[string]interface{}{"httpClient.enableTraceInfo": true},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "firstsecond", string(resp.Body))
+	assert.GreaterOrEqual(t, resp.Time, 200*time.Millisecond)
+	assert.GreaterOrEqual(t, resp.TraceInfo.TotalTime, 200*time.Millisecond)
+	assert.GreaterOrEqual(t, resp.TraceInfo.ResponseTime, 200*time.Millisecond)
+}
+
+func TestDoRequestWithMultipartBody(t *testing.T) {
+	dir := t.TempDir()
+	pngPath := filepath.Join(dir, "a.png")
+	dataPath := filepath.Join(dir, "data")
+	assert.Nil(t, os.WriteFile(pngPath, []byte("\x89PNG\r\n\x1a\n"), 0o644))
+	assert.Nil(t, os.WriteFile(dataPath, []byte("%PDF-1.4"), 0o644))
+
+	var calls atomic.Int32
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// parts have to be sent again when retrying
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		reader, err := r.MultipartReader()
+		assert.Nil(t, err)
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			content := new(strings.Builder)
+			_, _ = io.Copy(content, part)
+			received = append(received, fmt.Sprintf("%s|%s|%s|%s", part.FormName(), part.FileName(), part.Header.Get("Content-Type"), content))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := DoRequest(model.Request{
+		Url:    server.URL,
+		Method: http.MethodPost,
+		Root:   dir,
+		Headers: model.Headers{
+			"Content-Type": {"multipart/form-data"},
+		},
+		Body: map[string]interface{}{
+			"title": "Image",
+			"images": []interface{}{
+				// relative to the workspace
+				"@a.png",
+				map[string]interface{}{"file": dataPath, "filename": "doc.pdf"},
+			},
+			"metadata": map[string]interface{}{"content": `{"a":1}`, "content_type": "application/json"},
+			"notes":    map[string]interface{}{"content": "x", "filename": "notes.txt"},
+		},
+		Options: map[string]interface{}{
+			"httpClient.retry.count":          1,
+			"httpClient.retry.waitSeconds":    0,
+			"httpClient.retry.maxWaitSeconds": 0,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{
+		"images|a.png|image/png|\x89PNG\r\n\x1a\n",
+		"images|doc.pdf|application/pdf|%PDF-1.4",
+		`metadata||application/json|{"a":1}`,
+		"notes|notes.txt|text/plain; charset=utf-8|x",
+		"title|||Image",
+	}, received)
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/configuration"
+	"github.com/susiteemu/startpoint/core/grpc"
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/tools/conv"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// grpcConnKey identifies a pooled grpc connection. Requests to the same target with equal
+// transport configuration share a connection.
+type grpcConnKey struct {
+	transport transportKey
+	target    string
+}
+
+var (
+	grpcConns   = map[grpcConnKey]*grpc.Connection{}
+	grpcConnsMu sync.Mutex
+)
+
+// grpcConnectionFor returns a pooled connection to target, creating one if needed
+func grpcConnectionFor(config *configuration.Configuration, target string) (*grpc.Connection, error) {
+	transportKey, _ := newTransportKey(config)
+	key := grpcConnKey{transport: transportKey, target: target}
+
+	grpcConnsMu.Lock()
+	defer grpcConnsMu.Unlock()
+	if conn, found := grpcConns[key]; found {
+		return conn, nil
+	}
+
+	transport, err := transportFor(config, "")
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.Dial(target, transport.TLSClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Msgf("Created grpc connection to %s", target)
+	grpcConns[key] = conn
+	return conn, nil
+}
+
+// DoGrpc calls the grpc method of the request. Its schema is read from the proto files of the
+// request or resolved using server reflection. Request body is JSON; for client streaming
+// methods a JSON array sends each of its elements as a message. The response body is the
+// response message as JSON, or an array of messages for server streaming methods.
+func DoGrpc(request model.Request) (*model.Response, error) {
+	if request.Grpc == nil || request.Grpc.Service == "" || request.Grpc.Method == "" {
+		return nil, fmt.Errorf("grpc request needs both service and method")
+	}
+	config := configuration.NewWithRequestOptions(request.Options)
+
+	conn, err := grpcConnectionFor(config, request.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	timeoutSeconds, set := config.GetInt("httpClient.timeoutSeconds")
+	if set && timeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+		defer cancel()
+	}
+	metadata := http.Header{}
+	for name, values := range request.Headers {
+		metadata[name] = values
+	}
+
+	start := time.Now()
+	var registry *grpc.Registry
+	if len(request.Grpc.ProtoFiles) > 0 {
+		registry, err = grpc.ParseFiles(request.Grpc.ProtoFiles, request.Grpc.ImportPaths)
+	} else {
+		registry, err = grpc.Reflect(ctx, conn, metadata, request.Grpc.Service)
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to resolve grpc service %s", request.Grpc.Service)
+		return nil, err
+	}
+	method, err := registry.FindMethod(request.Grpc.Service, request.Grpc.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := grpcBody(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	messages := grpcMessages(method, body)
+
+	fullMethod := fmt.Sprintf("%s/%s", method.Parent().FullName(), method.Name())
+	result, err := conn.Invoke(ctx, registry, method, metadata, messages)
+	if err != nil {
+		log.Error().Err(err).Msgf("Grpc call %s failed", fullMethod)
+		return nil, err
+	}
+
+	code := result.Status.Code()
+	var responseBody []byte
+	if code != codes.OK {
+		responseBody, err = json.Marshal(map[string]interface{}{
+			"code":    code,
+			"status":  grpc.CodeName(code),
+			"message": result.Status.Message(),
+		})
+	} else {
+		responseBody = joinGrpcMessages(method, result.Messages)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	headers := model.Headers{}
+	for _, source := range []http.Header{result.Header, result.Trailer} {
+		for name, values := range source {
+			headers[name] = values
+		}
+	}
+	headers[model.HEADER_NAME_CONTENT_TYPE] = model.HeaderValues{model.CONTENT_TYPE_APPLICATION_JSON}
+
+	return &model.Response{
+		Headers:    headers,
+		Body:       responseBody,
+		Status:     fmt.Sprintf("%d %s", code, grpc.CodeName(code)),
+		StatusCode: grpc.HttpStatus(code),
+		Proto:      model.PROTOCOL_GRPC,
+		Size:       int64(len(responseBody)),
+		ReceivedAt: time.Now(),
+		Time:       time.Since(start),
+		Options:    request.Options,
+		Request: model.Request{
+			Url:      strings.TrimSuffix(request.Url, "/") + "/" + fullMethod,
+			Method:   http.MethodPost,
+			Headers:  request.Headers,
+			Body:     string(body),
+			Protocol: model.PROTOCOL_GRPC,
+			Grpc:     request.Grpc,
+		},
+	}, nil
+}
+
+// grpcBody returns body of the request as JSON
+func grpcBody(body model.Body) ([]byte, error) {
+	switch b := body.(type) {
+	case nil:
+		return []byte("{}"), nil
+	case string:
+		return []byte(b), nil
+	case []byte:
+		return b, nil
+	}
+	if asMap, ok := conv.ConvertMapOfInterfaceToString(body); ok {
+		return json.Marshal(asMap)
+	}
+	return json.Marshal(body)
+}
+
+// grpcMessages splits body to messages. For client streaming methods a JSON array sends each of
+// its elements as a message.
+func grpcMessages(method protoreflect.MethodDescriptor, body []byte) [][]byte {
+	if method.IsStreamingClient() {
+		var list []json.RawMessage
+		if json.Unmarshal(body, &list) == nil {
+			var messages [][]byte
+			for _, item := range list {
+				messages = append(messages, item)
+			}
+			return messages
+		}
+	}
+	return [][]byte{body}
+}
+
+// joinGrpcMessages returns the response message, or an array of messages for server streaming
+// methods
+func joinGrpcMessages(method protoreflect.MethodDescriptor, messages [][]byte) []byte {
+	if method.IsStreamingServer() {
+		return append(append([]byte("["), bytes.Join(messages, []byte(","))...), ']')
+	}
+	if len(messages) == 0 {
+		return []byte("{}")
+	}
+	return messages[len(messages)-1]
+}
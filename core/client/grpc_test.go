@@ -0,0 +1,27 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/susiteemu/startpoint/core/configuration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrpcConnectionsArePooled(t *testing.T) {
+	config := configuration.NewWithRequestOptions(map[string]interface{}{})
+	conn, err := grpcConnectionFor(config, "grpc://localhost:50051")
+	assert.Nil(t, err)
+	same, err := grpcConnectionFor(config, "grpc://localhost:50051")
+	assert.Nil(t, err)
+	assert.Same(t, conn, same)
+
+	other, err := grpcConnectionFor(config, "grpc://localhost:50052")
+	assert.Nil(t, err)
+	assert.NotSame(t, conn, other)
+
+	insecure := configuration.NewWithRequestOptions(map[string]interface{}{"httpClient.insecure": true})
+	other, err = grpcConnectionFor(insecure, "grpc://localhost:50051")
+	assert.Nil(t, err)
+	assert.NotSame(t, conn, other)
+}
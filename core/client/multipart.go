@@ -0,0 +1,89 @@
+package client
+
+import (
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/tools/paths"
+	"github.com/susiteemu/startpoint/core/tools/sniff"
+)
+
+// multipartFields converts parts of the body to multipart fields. Files are relative to the
+// workspace of the request. Content type of a file defaults to the one of its extension and then
+// to the one sniffed from its content.
+func multipartFields(request model.Request) ([]*resty.MultipartField, error) {
+	parts, err := request.MultipartParts()
+	if err != nil {
+		return nil, err
+	}
+	var fields []*resty.MultipartField
+	for _, part := range parts {
+		field := &resty.MultipartField{
+			Param:       part.Name,
+			FileName:    part.Filename,
+			ContentType: part.ContentType,
+		}
+		if len(part.File) > 0 {
+			path := paths.Resolve(request.Root, part.File)
+			if len(field.FileName) == 0 {
+				field.FileName = filepath.Base(path)
+			}
+			if len(field.ContentType) == 0 {
+				field.ContentType, err = fileContentType(path)
+				if err != nil {
+					log.Error().Err(err).Msgf("Failed to read multipart file %s", path)
+					return nil, err
+				}
+			}
+			field.Reader = &rewindingReader{open: func() (io.ReadCloser, error) {
+				return os.Open(path)
+			}}
+		} else {
+			if len(field.ContentType) == 0 && len(field.FileName) > 0 {
+				field.ContentType = mime.TypeByExtension(filepath.Ext(field.FileName))
+			}
+			content := part.Content
+			field.Reader = &rewindingReader{open: func() (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader(content)), nil
+			}}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func fileContentType(path string) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); len(contentType) > 0 {
+		return contentType, nil
+	}
+	return sniff.FileContentType(path)
+}
+
+// rewindingReader opens its content when read and starts over after it has been read to the
+// end so that a retried request sends the whole part again
+type rewindingReader struct {
+	open   func() (io.ReadCloser, error)
+	reader io.ReadCloser
+}
+
+func (r *rewindingReader) Read(p []byte) (int, error) {
+	if r.reader == nil {
+		reader, err := r.open()
+		if err != nil {
+			return 0, err
+		}
+		r.reader = reader
+	}
+	n, err := r.reader.Read(p)
+	if err != nil {
+		r.reader.Close()
+		r.reader = nil
+	}
+	return n, err
+}
@@ -0,0 +1,277 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/configuration"
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	OAUTH2_GRANT_CLIENT_CREDENTIALS = "client_credentials"
+	OAUTH2_GRANT_PASSWORD           = "password"
+	OAUTH2_GRANT_REFRESH_TOKEN      = "refresh_token"
+
+	OAUTH2_CLIENT_AUTH_BASIC = "basic"
+	OAUTH2_CLIENT_AUTH_BODY  = "body"
+)
+
+// tokens are refreshed this much before they expire to allow for clock skew and request time
+const oauth2ExpiryMargin = 10 * time.Second
+
+// OAUTH2_TOKENS_FILE holds tokens of the workspace so that they are reused over runs
+const OAUTH2_TOKENS_FILE = ".startpoint/oauth2_tokens.json"
+
+type oauth2Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	expiresAt    time.Time
+}
+
+// storedOAuth2Token is a token as persisted under workspace
+type storedOAuth2Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+var (
+	oauth2Tokens   = map[string]*oauth2Token{}
+	oauth2TokensMu sync.Mutex
+)
+
+func (t *oauth2Token) valid(now time.Time) bool {
+	return len(t.AccessToken) > 0 && (t.expiresAt.IsZero() || now.Before(t.expiresAt))
+}
+
+func (t *oauth2Token) authorization() string {
+	tokenType := t.TokenType
+	if len(tokenType) == 0 || strings.EqualFold(tokenType, model.HEADER_VALUE_BEARER_AUTH) {
+		tokenType = model.HEADER_VALUE_BEARER_AUTH
+	}
+	return fmt.Sprintf("%s %s", tokenType, t.AccessToken)
+}
+
+// oauth2Authorization returns value for Authorization header using a cached token when still
+// valid. Expired tokens are refreshed with their refresh token if the server issued one. Tokens
+// are cached in memory and, when root is set, in the workspace for later runs.
+func oauth2Authorization(auth model.OAuth2Auth, root string, config *configuration.Configuration) (string, error) {
+	if len(auth.TokenUrl) == 0 {
+		return "", errors.New("oauth2 token_url must not be empty")
+	}
+	key := oauth2CacheKey(auth)
+
+	oauth2TokensMu.Lock()
+	defer oauth2TokensMu.Unlock()
+
+	now := time.Now()
+	cached, found := oauth2Tokens[key]
+	if !found && len(root) > 0 {
+		cached, found = readOAuth2Token(root, key)
+	}
+	if found && cached.valid(now) {
+		log.Debug().Msgf("Using cached oauth2 token from %s", auth.TokenUrl)
+		return cached.authorization(), nil
+	}
+
+	var token *oauth2Token
+	var err error
+	if found && len(cached.RefreshToken) > 0 {
+		refresh := auth
+		refresh.GrantType = OAUTH2_GRANT_REFRESH_TOKEN
+		refresh.RefreshToken = cached.RefreshToken
+		token, err = fetchOAuth2Token(refresh, config)
+		if err != nil {
+			log.Warn().Err(err).Msg("Refreshing oauth2 token failed, requesting a new one")
+		}
+	}
+	if token == nil {
+		token, err = fetchOAuth2Token(auth, config)
+		if err != nil {
+			return "", err
+		}
+	}
+	if len(token.RefreshToken) == 0 && found {
+		// keep refresh token when server does not rotate it
+		token.RefreshToken = cached.RefreshToken
+	}
+	oauth2Tokens[key] = token
+	if len(root) > 0 {
+		err = writeOAuth2Token(root, key, token)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to store oauth2 token from %s", auth.TokenUrl)
+		}
+	}
+	return token.authorization(), nil
+}
+
+func fetchOAuth2Token(auth model.OAuth2Auth, config *configuration.Configuration) (*oauth2Token, error) {
+	form := map[string]string{
+		"grant_type": auth.GrantType,
+	}
+	switch auth.GrantType {
+	case OAUTH2_GRANT_CLIENT_CREDENTIALS:
+	case OAUTH2_GRANT_PASSWORD:
+		form["username"] = auth.Username
+		form["password"] = auth.Password
+	case OAUTH2_GRANT_REFRESH_TOKEN:
+		if len(auth.RefreshToken) == 0 {
+			return nil, errors.New("oauth2 refresh_token must not be empty")
+		}
+		form["refresh_token"] = auth.RefreshToken
+	default:
+		return nil, fmt.Errorf("unsupported oauth2 grant_type %s", auth.GrantType)
+	}
+	if len(auth.Scopes) > 0 {
+		form["scope"] = strings.Join(auth.Scopes, " ")
+	}
+
+	transport, err := transportFor(config, "")
+	if err != nil {
+		return nil, err
+	}
+	r := resty.New().SetLogger(newLogger(&log.Logger)).SetTransport(transport).R().
+		SetHeader("Accept", "application/json")
+
+	switch auth.ClientAuth {
+	case "", OAUTH2_CLIENT_AUTH_BASIC:
+		if len(auth.ClientSecret) > 0 {
+			r.SetBasicAuth(auth.ClientId, auth.ClientSecret)
+		} else if len(auth.ClientId) > 0 {
+			form["client_id"] = auth.ClientId
+		}
+	case OAUTH2_CLIENT_AUTH_BODY:
+		if len(auth.ClientId) > 0 {
+			form["client_id"] = auth.ClientId
+		}
+		if len(auth.ClientSecret) > 0 {
+			form["client_secret"] = auth.ClientSecret
+		}
+	default:
+		return nil, fmt.Errorf("unsupported oauth2 client_auth %s", auth.ClientAuth)
+	}
+
+	log.Info().Msgf("Fetching oauth2 token from %s with grant %s", auth.TokenUrl, auth.GrantType)
+	resp, err := r.SetFormData(form).Post(auth.TokenUrl)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("oauth2 token request failed with status %s: %s", resp.Status(), string(resp.Body()))
+	}
+
+	token := &oauth2Token{}
+	err = json.Unmarshal(resp.Body(), token)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to parse oauth2 token response %s", string(resp.Body()))
+		return nil, err
+	}
+	if len(token.AccessToken) == 0 {
+		return nil, errors.New("oauth2 token response has no access_token")
+	}
+	if token.ExpiresIn > 0 {
+		expiresIn := time.Duration(token.ExpiresIn) * time.Second
+		token.expiresAt = time.Now().Add(expiresIn - min(oauth2ExpiryMargin, expiresIn/2))
+	}
+	return token, nil
+}
+
+func oauth2CacheKey(auth model.OAuth2Auth) string {
+	return strings.Join([]string{
+		auth.TokenUrl,
+		auth.GrantType,
+		auth.ClientId,
+		auth.ClientSecret,
+		auth.Username,
+		auth.Password,
+		auth.RefreshToken,
+		strings.Join(auth.Scopes, " "),
+	}, "\x00")
+}
+
+func readOAuth2Tokens(root string) (map[string]storedOAuth2Token, error) {
+	tokens := map[string]storedOAuth2Token{}
+	file, err := os.ReadFile(filepath.Join(root, OAUTH2_TOKENS_FILE))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return tokens, nil
+		}
+		return nil, err
+	}
+	err = json.Unmarshal(file, &tokens)
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func readOAuth2Token(root, key string) (*oauth2Token, bool) {
+	tokens, err := readOAuth2Tokens(root)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read stored oauth2 tokens")
+		return nil, false
+	}
+	stored, found := tokens[oauth2StoreKey(key)]
+	if !found {
+		return nil, false
+	}
+	return &oauth2Token{
+		AccessToken:  stored.AccessToken,
+		TokenType:    stored.TokenType,
+		RefreshToken: stored.RefreshToken,
+		expiresAt:    stored.ExpiresAt,
+	}, true
+}
+
+func writeOAuth2Token(root, key string, token *oauth2Token) error {
+	tokens, err := readOAuth2Tokens(root)
+	if err != nil {
+		// start over rather than keep failing
+		tokens = map[string]storedOAuth2Token{}
+	}
+	now := time.Now()
+	for k, stored := range tokens {
+		if len(stored.RefreshToken) == 0 && !stored.ExpiresAt.IsZero() && stored.ExpiresAt.Before(now) {
+			delete(tokens, k)
+		}
+	}
+	tokens[oauth2StoreKey(key)] = storedOAuth2Token{
+		AccessToken:  token.AccessToken,
+		TokenType:    token.TokenType,
+		RefreshToken: token.RefreshToken,
+		ExpiresAt:    token.expiresAt,
+	}
+	contents, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(root, OAUTH2_TOKENS_FILE)
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	// tokens are credentials so keep them private
+	return os.WriteFile(path, contents, 0600)
+}
+
+// oauth2StoreKey hashes the cache key so that client secrets and passwords are not written to disk
+func oauth2StoreKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoRequestWithOAuth2(t *testing.T) {
+	var issued atomic.Int32
+	var lastGrant atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			r.ParseForm()
+			user, secret, _ := r.BasicAuth()
+			grant := r.PostForm.Get("grant_type")
+			if user != "my-client" || secret != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if grant == OAUTH2_GRANT_REFRESH_TOKEN && r.PostForm.Get("refresh_token") != "refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			lastGrant.Store(grant)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":3600,"refresh_token":"refresh"}`, issued.Add(1))
+		default:
+			w.Write([]byte(r.Header.Get("Authorization")))
+		}
+	}))
+	defer server.Close()
+
+	doRequest := func(scope string) string {
+		resp, err := DoRequest(model.Request{
+			Url:     server.URL + "/me",
+			Method:  http.MethodGet,
+			Headers: model.Headers{},
+			OAuth2: &model.OAuth2Auth{
+				GrantType:    OAUTH2_GRANT_CLIENT_CREDENTIALS,
+				TokenUrl:     server.URL + "/token",
+				ClientId:     "my-client",
+				ClientSecret: "secret",
+				Scopes:       []string{scope},
+			},
+		})
+		assert.Nil(t, err)
+		return string(resp.Body)
+	}
+
+	// token is cached
+	assert.Equal(t, "Bearer token-1", doRequest("read"))
+	assert.Equal(t, "Bearer token-1", doRequest("read"))
+	assert.Equal(t, int32(1), issued.Load())
+
+	// expired token is refreshed
+	assert.Equal(t, "Bearer token-2", doRequest("write"))
+	oauth2TokensMu.Lock()
+	for _, token := range oauth2Tokens {
+		token.expiresAt = time.Now().Add(-time.Second)
+	}
+	oauth2TokensMu.Unlock()
+	assert.Equal(t, "Bearer token-3", doRequest("write"))
+	assert.Equal(t, OAUTH2_GRANT_REFRESH_TOKEN, lastGrant.Load())
+	assert.Equal(t, int32(3), issued.Load())
+
+	_, err := DoRequest(model.Request{
+		Url:     server.URL + "/me",
+		Method:  http.MethodGet,
+		Headers: model.Headers{},
+		OAuth2: &model.OAuth2Auth{
+			GrantType: OAUTH2_GRANT_CLIENT_CREDENTIALS,
+			TokenUrl:  server.URL + "/token",
+			ClientId:  "other-client",
+		},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestDoRequestWithOAuth2StoresTokensInWorkspace(t *testing.T) {
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token":"stored-%d","expires_in":3600}`, issued.Add(1))
+		default:
+			w.Write([]byte(r.Header.Get("Authorization")))
+		}
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	auth := &model.OAuth2Auth{
+		GrantType:    OAUTH2_GRANT_CLIENT_CREDENTIALS,
+		TokenUrl:     server.URL + "/token",
+		ClientId:     "stored-client",
+		ClientSecret: "secret",
+	}
+	doRequest := func() string {
+		resp, err := DoRequest(model.Request{
+			Url:     server.URL + "/me",
+			Method:  http.MethodGet,
+			Headers: model.Headers{},
+			OAuth2:  auth,
+			Root:    root,
+		})
+		assert.Nil(t, err)
+		return string(resp.Body)
+	}
+
+	assert.Equal(t, "Bearer stored-1", doRequest())
+
+	// a later run starts with an empty memory cache
+	oauth2TokensMu.Lock()
+	delete(oauth2Tokens, oauth2CacheKey(*auth))
+	oauth2TokensMu.Unlock()
+	assert.Equal(t, "Bearer stored-1", doRequest())
+	assert.Equal(t, int32(1), issued.Load())
+
+	stored, err := os.ReadFile(filepath.Join(root, OAUTH2_TOKENS_FILE))
+	assert.Nil(t, err)
+	assert.Contains(t, string(stored), "stored-1")
+	assert.NotContains(t, string(stored), "secret")
+}
@@ -4,17 +4,54 @@ import (
 	"errors"
 	"github.com/susiteemu/startpoint/core/client"
 	"github.com/susiteemu/startpoint/core/client/builder"
+	"github.com/susiteemu/startpoint/core/configuration"
+	"github.com/susiteemu/startpoint/core/history"
 	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/session"
+	"maps"
 	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
 func RunRequestChain(reqs []*model.RequestMold, profile *model.Profile, interimResultCb func(took time.Duration, statusCode int)) ([]*model.Response, error) {
+	// cookies live for the length of the chain, auth headers are not shared without a named session
+	return RunRequestChainInSession(reqs, profile, session.New(), interimResultCb)
+}
+
+// RunRequestChainInSession runs the chain sharing cookies and captured auth headers of the given session.
+// Saving the session is left to the caller.
+func RunRequestChainInSession(reqs []*model.RequestMold, profile *model.Profile, sess *session.Session, interimResultCb func(took time.Duration, statusCode int)) ([]*model.Response, error) {
+	return RunRequestChainWithEvents(reqs, profile, sess, interimResultCb, nil)
+}
+
+// RunRequestChainWithEvents runs the chain like RunRequestChainInSession and passes server-sent events
+// of the requests to eventCb as they arrive. With nil eventCb responses are read fully before returning.
+func RunRequestChainWithEvents(reqs []*model.RequestMold, profile *model.Profile, sess *session.Session, interimResultCb func(took time.Duration, statusCode int), eventCb func(requestName string, event model.Event)) ([]*model.Response, error) {
+	return RunRequestChainWithOptions(reqs, profile, sess, Options{}, interimResultCb, eventCb)
+}
+
+// Options changes how the requests of a chain are run
+type Options struct {
+	// Body replaces the body of the last request of the chain when not nil, e.g. with a body read
+	// from stdin
+	Body model.Body
+	// RequestOptions are set to the options of every request of the chain, e.g. to enable
+	// trace info without changing the configuration
+	RequestOptions map[string]interface{}
+	// SkipHistory leaves the responses out of history even if it is enabled
+	SkipHistory bool
+}
+
+// RunRequestChainWithOptions runs the chain like RunRequestChainWithEvents changing it with opts
+func RunRequestChainWithOptions(reqs []*model.RequestMold, profile *model.Profile, sess *session.Session, opts Options, interimResultCb func(took time.Duration, statusCode int), eventCb func(requestName string, event model.Event)) ([]*model.Response, error) {
 
 	if reqs == nil {
 		return nil, errors.New("Requests must not be nil")
 	}
+	if sess == nil {
+		return nil, errors.New("Session must not be nil")
+	}
 	if profile == nil {
 		// if passed nil profile we create empty one
 		profile = &model.Profile{}
@@ -24,7 +61,7 @@ func RunRequestChain(reqs []*model.RequestMold, profile *model.Profile, interimR
 
 	var responses []*model.Response
 	var prevResponse *model.Response
-	for _, r := range reqs {
+	for i, r := range reqs {
 		log.Debug().Msgf("Building request %v", r)
 		var request model.Request
 		var err error
@@ -38,14 +75,50 @@ func RunRequestChain(reqs []*model.RequestMold, profile *model.Profile, interimR
 			log.Error().Err(err).Msgf("Building request failed with %v", r)
 			return responses, err
 		}
+		if opts.Body != nil && i == len(reqs)-1 {
+			request.Body = opts.Body
+		}
+		if len(opts.RequestOptions) > 0 {
+			// options of the request may be shared with the mold
+			request.Options = maps.Clone(request.Options)
+			if request.Options == nil {
+				request.Options = map[string]interface{}{}
+			}
+			maps.Copy(request.Options, opts.RequestOptions)
+		}
+
+		sess.Apply(&request)
+		if eventCb != nil {
+			name := r.Name
+			request.OnEvent = func(event model.Event) {
+				eventCb(name, event)
+			}
+		}
 
-		response, err := client.DoRequest(request)
+		var response *model.Response
+		switch request.Protocol {
+		case model.PROTOCOL_WEBSOCKET:
+			response, err = client.DoWebSocket(request)
+		case model.PROTOCOL_GRPC:
+			response, err = client.DoGrpc(request)
+		default:
+			response, err = client.DoRequest(request)
+		}
 		if err != nil {
 			log.Error().Err(err).Msgf("Request failed with %v", request)
 			return responses, err
 		}
+		sess.Capture(request)
 		response.RequestName = r.Name
 
+		recordHistory := configuration.NewWithRequestOptions(request.Options).GetBoolWithDefault("history.enabled", false)
+		if recordHistory && !opts.SkipHistory && len(r.Root) > 0 {
+			err = history.Save(r.Root, response)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Failed to save history entry of %s", r.Name)
+			}
+		}
+
 		interimResultCb(response.Time, response.StatusCode)
 
 		responses = append(responses, response)
@@ -55,3 +128,31 @@ func RunRequestChain(reqs []*model.RequestMold, profile *model.Profile, interimR
 	return responses, nil
 
 }
+
+// PrepareRequest runs the requests of the chain before the last one and builds the last one
+// using the response of the previous request
+func PrepareRequest(reqs []*model.RequestMold, profile *model.Profile, sess *session.Session) (model.Request, error) {
+	if len(reqs) == 0 {
+		return model.Request{}, errors.New("Requests must not be empty")
+	}
+	if profile == nil {
+		profile = &model.Profile{}
+	}
+	last := reqs[len(reqs)-1]
+	responses, err := RunRequestChainInSession(reqs[:len(reqs)-1], profile, sess, func(took time.Duration, statusCode int) {})
+	if err != nil {
+		return model.Request{}, err
+	}
+
+	var request model.Request
+	if len(responses) > 0 {
+		request, err = builder.BuildRequestUsingPreviousResponse(last, responses[len(responses)-1], *profile)
+	} else {
+		request, err = builder.BuildRequest(last, *profile)
+	}
+	if err != nil {
+		return model.Request{}, err
+	}
+	sess.Apply(&request)
+	return request, nil
+}
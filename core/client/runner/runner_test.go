@@ -0,0 +1,125 @@
+package runner
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/session"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunRequestChainSharesCookies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: "abc", Path: "/"})
+			w.WriteHeader(http.StatusOK)
+		default:
+			cookie, err := r.Cookie("sid")
+			if err != nil || cookie.Value != "abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	reqs := []*model.RequestMold{
+		{
+			Name: "Login",
+			Type: model.CONTENT_TYPE_YAML,
+			Yaml: &model.YamlRequest{Url: server.URL + "/login", Method: "POST"},
+		},
+		{
+			Name: "Me",
+			Type: model.CONTENT_TYPE_YAML,
+			Yaml: &model.YamlRequest{PrevReq: "Login", Url: server.URL + "/me", Method: "GET"},
+		},
+	}
+
+	responses, err := RunRequestChain(reqs, nil, func(took time.Duration, statusCode int) {})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(responses))
+	assert.Equal(t, http.StatusOK, responses[1].StatusCode)
+
+	// a new chain starts without cookies
+	responses, err = RunRequestChain(reqs[1:], nil, func(took time.Duration, statusCode int) {})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, responses[0].StatusCode)
+}
+
+func TestRunRequestChainDoesNotShareAuthorization(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	reqs := []*model.RequestMold{
+		{
+			Name: "Login",
+			Type: model.CONTENT_TYPE_YAML,
+			Yaml: &model.YamlRequest{
+				Url:     server.URL + "/login",
+				Method:  "POST",
+				Headers: model.Headers{"Authorization": {"Bearer token"}},
+			},
+		},
+		{
+			Name: "Me",
+			Type: model.CONTENT_TYPE_YAML,
+			Yaml: &model.YamlRequest{PrevReq: "Login", Url: server.URL + "/me", Method: "GET"},
+		},
+	}
+
+	_, err := RunRequestChain(reqs, nil, func(took time.Duration, statusCode int) {})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Bearer token", ""}, authorizations)
+
+	// a named session replays the captured header
+	authorizations = nil
+	sess, err := session.Load(t.TempDir(), "work")
+	assert.Nil(t, err)
+	_, err = RunRequestChainInSession(reqs, nil, sess, func(took time.Duration, statusCode int) {})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Bearer token", "Bearer token"}, authorizations)
+}
+
+func TestRunRequestChainWithBodyReplacesBodyOfLastRequest(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	reqs := []*model.RequestMold{
+		{
+			Name: "Login",
+			Type: model.CONTENT_TYPE_YAML,
+			Yaml: &model.YamlRequest{Url: server.URL + "/login", Method: "POST", Body: "login"},
+		},
+		{
+			Name: "Add pet",
+			Type: model.CONTENT_TYPE_YAML,
+			Yaml: &model.YamlRequest{PrevReq: "Login", Url: server.URL + "/pets", Method: "POST", Body: "from request"},
+		},
+	}
+
+	_, err := RunRequestChainWithOptions(reqs, nil, session.New(), Options{Body: "from stdin"}, func(took time.Duration, statusCode int) {}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"login", "from stdin"}, bodies)
+
+	// the request itself is left as it was
+	_, err = RunRequestChain(reqs[1:], nil, func(took time.Duration, statusCode int) {})
+	assert.Nil(t, err)
+	assert.Equal(t, "from request", bodies[2])
+}
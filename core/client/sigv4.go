@@ -0,0 +1,172 @@
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/model"
+)
+
+const (
+	AWS_SIGV4_ALGORITHM = "AWS4-HMAC-SHA256"
+
+	awsDateFormat      = "20060102T150405Z"
+	awsDateStampFormat = "20060102"
+)
+
+// signAwsSigV4 signs the request with AWS Signature Version 4. The body is read to compute its
+// hash and replaced so that it can still be sent.
+func signAwsSigV4(req *http.Request, auth model.AwsSigV4Auth, now time.Time) error {
+	if len(auth.AccessKey) == 0 || len(auth.SecretKey) == 0 {
+		return errors.New("aws_sigv4 access_key and secret_key must not be empty")
+	}
+	if len(auth.Region) == 0 || len(auth.Service) == 0 {
+		return errors.New("aws_sigv4 region and service must not be empty")
+	}
+
+	body := []byte{}
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	payloadHash := sha256Hex(body)
+
+	amzDate := now.UTC().Format(awsDateFormat)
+	dateStamp := now.UTC().Format(awsDateStampFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if len(auth.SessionToken) > 0 {
+		req.Header.Set("X-Amz-Security-Token", auth.SessionToken)
+	}
+	if auth.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	host := req.Host
+	if len(host) == 0 {
+		host = req.URL.Host
+	}
+	signedHeaders, canonicalHeaders := canonicalAwsHeaders(req.Header, host)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalAwsUri(req.URL, auth.Service),
+		canonicalAwsQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{dateStamp, auth.Region, auth.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		AWS_SIGV4_ALGORITHM,
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSha256([]byte("AWS4"+auth.SecretKey), dateStamp)
+	signingKey = hmacSha256(signingKey, auth.Region)
+	signingKey = hmacSha256(signingKey, auth.Service)
+	signingKey = hmacSha256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+
+	req.Header.Set(model.HEADER_NAME_AUTHORIZATION, fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		AWS_SIGV4_ALGORITHM, auth.AccessKey, scope, signedHeaders, signature))
+	return nil
+}
+
+// canonicalAwsHeaders returns signed header names and canonical headers. Signed are host,
+// content type and amz headers, which are not changed by proxies or the transport.
+func canonicalAwsHeaders(header http.Header, host string) (string, string) {
+	values := map[string]string{"host": host}
+	for name, v := range header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "content-md5" || strings.HasPrefix(lower, "x-amz-") {
+			var trimmed []string
+			for _, value := range v {
+				trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+			}
+			values[lower] = strings.Join(trimmed, ",")
+		}
+	}
+	var names []string
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + values[name] + "\n")
+	}
+	return strings.Join(names, ";"), canonical.String()
+}
+
+func canonicalAwsUri(u *url.URL, service string) string {
+	path := u.EscapedPath()
+	if len(path) == 0 {
+		return "/"
+	}
+	if service == "s3" {
+		// s3 uses the path encoded once
+		return path
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = awsUriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func canonicalAwsQuery(query url.Values) string {
+	var pairs []string
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, awsUriEncode(key)+"="+awsUriEncode(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsUriEncode encodes everything but unreserved characters as required by sigv4
+func awsUriEncode(s string) string {
+	var encoded strings.Builder
+	for _, b := range []byte(s) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~' {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
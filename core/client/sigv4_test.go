@@ -0,0 +1,99 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// test cases come from the AWS Signature Version 4 test suite
+func TestSignAwsSigV4(t *testing.T) {
+	auth := model.AwsSigV4Auth{
+		AccessKey: "AKIDEXAMPLE",
+		SecretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:    "us-east-1",
+		Service:   "service",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		body          string
+		headers       map[string]string
+		expectedAuthz string
+	}{
+		{
+			name:          "get-vanilla",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/",
+			expectedAuthz: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "get-vanilla-query-order-key-case",
+			method:        http.MethodGet,
+			url:           "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			expectedAuthz: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:          "post-x-www-form-urlencoded",
+			method:        http.MethodPost,
+			url:           "https://example.amazonaws.com/",
+			body:          "Param1=value1",
+			headers:       map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			expectedAuthz: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			err := signAwsSigV4(req, auth, now)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedAuthz, req.Header.Get("Authorization"))
+			assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+		})
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	err := signAwsSigV4(req, model.AwsSigV4Auth{}, now)
+	assert.NotNil(t, err)
+}
+
+func TestDoRequestWithAwsSigV4(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.HasPrefix(r.Header.Get("Authorization"), AWS_SIGV4_ALGORITHM) || len(r.Header.Get("X-Amz-Content-Sha256")) == 0 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	resp, err := DoRequest(model.Request{
+		Url:     server.URL + "/bucket/key",
+		Method:  http.MethodPut,
+		Headers: model.Headers{"Content-Type": {"text/plain"}},
+		Body:    "hello",
+		AwsSigV4: &model.AwsSigV4Auth{
+			AccessKey: "minioadmin",
+			SecretKey: "minioadmin",
+			Region:    "us-east-1",
+			Service:   "s3",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "hello", string(resp.Body))
+}
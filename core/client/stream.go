@@ -0,0 +1,209 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/configuration"
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/sse"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultMaxReconnects = 5
+	// reconnection time used until the server sends its own
+	defaultReconnectWait = 3 * time.Second
+)
+
+// readStreamedBody reads the body of a response executed without parsing. Event streams are
+// parsed as they arrive and each event is passed to onEvent. When reconnecting is enabled, an
+// ended event stream is requested again with Last-Event-ID until the server stops it with a
+// non-200 response or the maximum number of reconnects is reached.
+func readStreamedBody(r *resty.Request, resp *resty.Response, method, url string, onEvent func(model.Event), maxBodyBytes int64, config *configuration.Configuration) (*rawBody, []model.Event, error) {
+	if !isEventStream(resp) {
+		body, err := readRawBody(resp, maxBodyBytes)
+		return body, nil, err
+	}
+
+	reconnect := config.GetBoolWithDefault("sse.reconnect", false)
+	maxReconnects, set := config.GetInt("sse.maxReconnects")
+	if !set || maxReconnects < 0 {
+		maxReconnects = defaultMaxReconnects
+	}
+
+	parser := sse.Parser{Retry: defaultReconnectWait}
+	var body bytes.Buffer
+	var events []model.Event
+	collect := func(event model.Event) {
+		events = append(events, event)
+		if onEvent != nil {
+			onEvent(event)
+		}
+	}
+
+	for reconnects := 0; ; reconnects++ {
+		stream, err := decodedBody(resp)
+		if err == nil {
+			err = parser.Parse(io.TeeReader(stream, &body), collect)
+		}
+		resp.RawBody().Close()
+		if err != nil {
+			log.Warn().Err(err).Msg("Event stream ended with error")
+		}
+
+		if !reconnect || reconnects >= maxReconnects {
+			break
+		}
+		log.Info().Msgf("Event stream ended, reconnecting in %s with last event id %s", parser.Retry, parser.LastEventId)
+		time.Sleep(parser.Retry)
+		if len(parser.LastEventId) > 0 {
+			r.SetHeader(sse.HEADER_NAME_LAST_EVENT_ID, parser.LastEventId)
+		}
+		next, err := r.Execute(method, url)
+		if err != nil {
+			log.Error().Err(err).Msg("Reconnecting to event stream failed")
+			break
+		}
+		if next.StatusCode() != http.StatusOK || !isEventStream(next) {
+			// e.g. 204 No Content tells the client to stop reconnecting
+			log.Info().Msgf("Stopped reconnecting to event stream after response %s", next.Status())
+			next.RawBody().Close()
+			break
+		}
+		resp = next
+	}
+	return &rawBody{bytes: body.Bytes(), size: int64(body.Len())}, events, nil
+}
+
+func isEventStream(resp *resty.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header().Get("Content-Type"))
+	return err == nil && mediaType == sse.CONTENT_TYPE_EVENT_STREAM
+}
+
+// logStreamedBody logs a body read by us like resty logs the bodies it reads when debugging
+func logStreamedBody(body *rawBody, took time.Duration) {
+	if len(body.file) > 0 {
+		log.Debug().Msgf("~~~ RESPONSE BODY ~~~\nTIME DURATION: %v\nBODY         :\n***** RESPONSE WRITTEN INTO FILE %s (size - %d) *****", took, body.file, body.size)
+		return
+	}
+	log.Debug().Msgf("~~~ RESPONSE BODY ~~~\nTIME DURATION: %v\nBODY         :\n%s", took, string(body.bytes))
+}
+
+// rawBody is a response body read by us. Bodies larger than the allowed maximum are written to
+// file and bytes holds only their beginning.
+type rawBody struct {
+	bytes []byte
+	file  string
+	size  int64
+}
+
+// readRawBody reads the body into memory. When maxBytes is positive and the body is larger, the
+// whole body is streamed to a temporary file and only its first maxBytes are kept in memory.
+func readRawBody(resp *resty.Response, maxBytes int64) (*rawBody, error) {
+	defer resp.RawBody().Close()
+	body, err := decodedBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	if maxBytes <= 0 {
+		all, err := io.ReadAll(body)
+		return &rawBody{bytes: all, size: int64(len(all))}, err
+	}
+
+	head, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(head)) <= maxBytes {
+		return &rawBody{bytes: head, size: int64(len(head))}, nil
+	}
+
+	file, err := os.CreateTemp("", "startpoint-body-*"+bodyFileExtension(resp))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create file for large body")
+		return nil, err
+	}
+	defer file.Close()
+	addBodyFile(file.Name())
+	size, err := io.Copy(file, io.MultiReader(bytes.NewReader(head), body))
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to write body to %s", file.Name())
+		RemoveBodyFile(file.Name())
+		return nil, err
+	}
+	log.Info().Msgf("Body of %d bytes is larger than %d bytes, wrote it to %s", size, maxBytes, file.Name())
+	return &rawBody{bytes: head[:maxBytes], file: file.Name(), size: size}, nil
+}
+
+var (
+	bodyFilesMu sync.Mutex
+	// bodyFiles holds the temporary files written for large bodies which have not been removed
+	bodyFiles = map[string]bool{}
+)
+
+func addBodyFile(path string) {
+	bodyFilesMu.Lock()
+	defer bodyFilesMu.Unlock()
+	bodyFiles[path] = true
+}
+
+// RemoveBodyFile removes the temporary file of a large response body. It is called when the
+// response is no longer needed, e.g. after it has been printed.
+func RemoveBodyFile(path string) {
+	bodyFilesMu.Lock()
+	defer bodyFilesMu.Unlock()
+	removeBodyFile(path)
+}
+
+// RemoveAllBodyFiles removes the temporary files of large response bodies that are left, e.g.
+// of the results kept by the TUI before exiting
+func RemoveAllBodyFiles() {
+	bodyFilesMu.Lock()
+	defer bodyFilesMu.Unlock()
+	for path := range bodyFiles {
+		removeBodyFile(path)
+	}
+}
+
+func removeBodyFile(path string) {
+	if !bodyFiles[path] {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Warn().Err(err).Msgf("Failed to remove body file %s", path)
+	}
+	delete(bodyFiles, path)
+}
+
+// bodyFileExtension returns a file extension matching content type of the response so that
+// pagers and editors can recognize the file
+func bodyFileExtension(resp *resty.Response) string {
+	mediaType, _, err := mime.ParseMediaType(resp.Header().Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	extensions, err := mime.ExtensionsByType(mediaType)
+	if err != nil || len(extensions) == 0 {
+		return ""
+	}
+	return extensions[0]
+}
+
+// decodedBody returns body reader decompressing gzip which transport leaves as is when
+// Accept-Encoding was set by the request
+func decodedBody(resp *resty.Response) (io.Reader, error) {
+	if strings.EqualFold(resp.Header().Get("Content-Encoding"), "gzip") && resp.RawResponse.ContentLength != 0 {
+		return gzip.NewReader(resp.RawBody())
+	}
+	return resp.RawBody(), nil
+}
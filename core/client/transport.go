@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/configuration"
+
+	"github.com/rs/zerolog/log"
+)
+
+// transportKey holds configuration affecting connections. Requests with equal keys share
+// a transport and thus can reuse its connections.
+type transportKey struct {
+	insecure           bool
+	proxyUrl           string
+	timeoutSeconds     int
+	rootCertificates   string
+	clientCertificates string
+	unixSocket         string
+}
+
+var (
+	transports   = map[transportKey]*http.Transport{}
+	transportsMu sync.Mutex
+)
+
+// transportFor returns a pooled transport matching configuration, creating one if needed.
+// NOTE: certificate files are read when the transport is created; changes to them are
+// picked up only by a new process.
+func transportFor(config *configuration.Configuration, unixSocket string) (*http.Transport, error) {
+	key, clientCertificates := newTransportKey(config)
+	key.unixSocket = unixSocket
+
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if transport, found := transports[key]; found {
+		return transport, nil
+	}
+
+	transport, err := newTransport(key, clientCertificates)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Msgf("Created transport for %+v", key)
+	transports[key] = transport
+	return transport, nil
+}
+
+func newTransportKey(config *configuration.Configuration) (transportKey, [][2]string) {
+	key := transportKey{}
+	key.insecure = config.GetBoolWithDefault("httpClient.insecure", false)
+	key.proxyUrl, _ = config.GetString("httpClient.proxyUrl")
+	timeoutSeconds, set := config.GetInt("httpClient.timeoutSeconds")
+	if set && timeoutSeconds > 0 {
+		key.timeoutSeconds = timeoutSeconds
+	}
+
+	var clientCertificates [][2]string
+	if !key.insecure {
+		rootCertificates, _ := config.GetStringSlice("httpClient.rootCertificates")
+		key.rootCertificates = strings.Join(rootCertificates, "\x00")
+
+		pairs, _ := config.GetSliceMapString("httpClient.clientCertificates")
+		var joined []string
+		for _, pair := range pairs {
+			// NOTE: viper makes all keys lowercase which is a bit annoying because
+			// configuration coming from request does not have lowercase keys: we now must handle both cases
+			certFile, found := pair["certfile"]
+			if !found {
+				certFile = pair["certFile"]
+			}
+			keyFile, found := pair["keyfile"]
+			if !found {
+				keyFile = pair["keyFile"]
+			}
+			clientCertificates = append(clientCertificates, [2]string{certFile, keyFile})
+			joined = append(joined, certFile+"\x00"+keyFile)
+		}
+		key.clientCertificates = strings.Join(joined, "\x00")
+	}
+	return key, clientCertificates
+}
+
+func newTransport(key transportKey, clientCertificates [][2]string) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = runtime.GOMAXPROCS(0) + 1
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if key.timeoutSeconds > 0 {
+		timeout := time.Duration(key.timeoutSeconds) * time.Second
+		dialer.Timeout = timeout
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = timeout
+	}
+
+	if len(key.unixSocket) > 0 {
+		// every connection goes to the socket regardless of host in url
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", key.unixSocket)
+		}
+		transport.Proxy = nil
+	} else if len(key.proxyUrl) > 0 {
+		proxyUrl, err := url.Parse(key.proxyUrl)
+		if err != nil {
+			log.Error().Err(err).Msgf("Invalid proxy url %s", key.proxyUrl)
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	tlsConfig := &tls.Config{}
+	if key.insecure {
+		tlsConfig.InsecureSkipVerify = true
+	} else {
+		if len(key.rootCertificates) > 0 {
+			tlsConfig.RootCAs = x509.NewCertPool()
+			for _, cert := range strings.Split(key.rootCertificates, "\x00") {
+				pem, err := os.ReadFile(cert)
+				if err != nil {
+					log.Error().Err(err).Msgf("Error with root certificate %s", cert)
+					continue
+				}
+				tlsConfig.RootCAs.AppendCertsFromPEM(pem)
+			}
+		}
+		for _, pair := range clientCertificates {
+			cert, err := tls.LoadX509KeyPair(pair[0], pair[1])
+			if err != nil {
+				log.Error().Err(err).Msg("Error with client certificate")
+				return nil, err
+			}
+			tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+		}
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
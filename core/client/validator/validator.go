@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"net/url"
 	"slices"
+
+	"github.com/susiteemu/startpoint/core/tools/urls"
 )
 
 var ValidMethods = []string{
@@ -29,6 +31,9 @@ func IsValidMethod(rawMethod string) bool {
 }
 
 func IsValidUrl(rawUrl string) bool {
+	if _, _, ok := urls.SplitUnixSocketUrl(rawUrl); ok {
+		return true
+	}
 	u, err := url.Parse(rawUrl)
 	return err == nil && u.Scheme != "" && u.Host != ""
 }
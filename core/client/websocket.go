@@ -0,0 +1,283 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/configuration"
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/websocket"
+)
+
+const defaultWebSocketTimeoutSeconds = 10
+
+// WebSocketConn is an open websocket connection. Received messages are read in the background
+// and delivered through Received.
+type WebSocketConn struct {
+	ws       *websocket.Conn
+	received chan model.WebSocketFrame
+	closed   chan struct{}
+	// err is set before received is closed
+	err error
+}
+
+// DialWebSocket opens a websocket connection to the url of the request sending its headers in
+// the opening handshake. Http urls are converted to their websocket counterparts.
+func DialWebSocket(request model.Request) (*WebSocketConn, error) {
+	config := configuration.NewWithRequestOptions(request.Options)
+
+	location, err := toWebSocketUrl(request.Url)
+	if err != nil {
+		return nil, err
+	}
+	origin := request.Headers.ToMap()["Origin"]
+	if len(origin) == 0 {
+		origin = strings.Replace(location.Scheme, "ws", "http", 1) + "://" + location.Host
+	}
+	wsConfig, err := websocket.NewConfig(location.String(), origin)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range request.Headers {
+		if http.CanonicalHeaderKey(name) == "Origin" {
+			continue
+		}
+		wsConfig.Header[name] = values
+	}
+
+	if request.CookieJar != nil {
+		httpUrl := *location
+		httpUrl.Scheme = strings.Replace(location.Scheme, "ws", "http", 1)
+		var cookies []string
+		for _, cookie := range request.CookieJar.Cookies(&httpUrl) {
+			cookies = append(cookies, cookie.Name+"="+cookie.Value)
+		}
+		if len(cookies) > 0 {
+			wsConfig.Header.Set(model.HEADER_NAME_COOKIE, strings.Join(cookies, "; "))
+		}
+	}
+
+	// reuse tls configuration of the http client so that certificate options apply
+	transport, err := transportFor(config, "")
+	if err != nil {
+		return nil, err
+	}
+	wsConfig.TlsConfig = transport.TLSClientConfig.Clone()
+	wsConfig.Dialer = &net.Dialer{Timeout: 30 * time.Second}
+	timeoutSeconds, set := config.GetInt("httpClient.timeoutSeconds")
+	if set && timeoutSeconds > 0 {
+		wsConfig.Dialer.Timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+
+	log.Info().Msgf("Opening websocket connection to %s", location)
+	ws, err := websocket.DialConfig(wsConfig)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to open websocket connection to %s", location)
+		return nil, err
+	}
+
+	conn := &WebSocketConn{
+		ws:       ws,
+		received: make(chan model.WebSocketFrame, 16),
+		closed:   make(chan struct{}),
+	}
+	go conn.read()
+	return conn, nil
+}
+
+func (c *WebSocketConn) read() {
+	for {
+		var data string
+		err := websocket.Message.Receive(c.ws, &data)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				c.err = err
+			}
+			close(c.received)
+			return
+		}
+		select {
+		case c.received <- model.WebSocketFrame{Data: data, Time: time.Now()}:
+		case <-c.closed:
+			close(c.received)
+			return
+		}
+	}
+}
+
+// Send sends a text message and returns it as a frame
+func (c *WebSocketConn) Send(data string) (model.WebSocketFrame, error) {
+	frame := model.WebSocketFrame{Sent: true, Data: data, Time: time.Now()}
+	return frame, websocket.Message.Send(c.ws, data)
+}
+
+// Received returns channel of received messages which is closed when the connection closes
+func (c *WebSocketConn) Received() <-chan model.WebSocketFrame {
+	return c.received
+}
+
+// Err returns error that closed the connection, nil when it was closed normally. Valid only
+// after Received has been closed.
+func (c *WebSocketConn) Err() error {
+	return c.err
+}
+
+func (c *WebSocketConn) Close() error {
+	select {
+	case <-c.closed:
+		return nil
+	default:
+		close(c.closed)
+	}
+	return c.ws.Close()
+}
+
+// DoWebSocket connects to the url of the request and sends its scripted messages. Received
+// messages are collected until one matches request.Until, the server closes the connection
+// or websocket.timeoutSeconds passes. Each received message is also passed to request.OnEvent.
+func DoWebSocket(request model.Request) (*model.Response, error) {
+	config := configuration.NewWithRequestOptions(request.Options)
+	timeoutSeconds, set := config.GetInt("websocket.timeoutSeconds")
+	if !set || timeoutSeconds <= 0 {
+		timeoutSeconds = defaultWebSocketTimeoutSeconds
+	}
+
+	until, err := compileOptional(request.Until)
+	if err != nil {
+		return nil, err
+	}
+	awaits := make([]*regexp.Regexp, len(request.Messages))
+	for i, message := range request.Messages {
+		awaits[i], err = compileOptional(message.Await)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	conn, err := DialWebSocket(request)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var frames []model.WebSocketFrame
+	var events []model.Event
+	deadline := time.After(time.Duration(timeoutSeconds) * time.Second)
+	// receive returns true when a message matched pattern; nil pattern matches nothing
+	receive := func(pattern *regexp.Regexp) (bool, error) {
+		for {
+			select {
+			case frame, ok := <-conn.Received():
+				if !ok {
+					return false, conn.Err()
+				}
+				frames = append(frames, frame)
+				event := model.Event{Data: frame.Data}
+				events = append(events, event)
+				if request.OnEvent != nil {
+					request.OnEvent(event)
+				}
+				if pattern != nil && pattern.MatchString(frame.Data) {
+					return true, nil
+				}
+			case <-deadline:
+				log.Info().Msgf("Websocket timed out after %d seconds", timeoutSeconds)
+				return false, nil
+			}
+		}
+	}
+
+	done := false
+	for i, message := range request.Messages {
+		frame, err := conn.Send(message.Send)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to send websocket message")
+			return nil, err
+		}
+		frames = append(frames, frame)
+		if awaits[i] != nil {
+			matched, err := receive(awaits[i])
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				done = true
+				break
+			}
+		}
+	}
+	if !done {
+		_, err = receive(until)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var body []string
+	for _, frame := range frames {
+		body = append(body, frame.String())
+	}
+	bodyBytes := []byte(strings.Join(body, "\n"))
+
+	headers := model.Headers{}
+	for name, values := range request.Headers {
+		headers[name] = values
+	}
+	return &model.Response{
+		Headers:    model.Headers{},
+		Body:       bodyBytes,
+		Status:     fmt.Sprintf("%d %s", http.StatusSwitchingProtocols, http.StatusText(http.StatusSwitchingProtocols)),
+		StatusCode: http.StatusSwitchingProtocols,
+		Proto:      model.PROTOCOL_WEBSOCKET,
+		Size:       int64(len(bodyBytes)),
+		ReceivedAt: time.Now(),
+		Time:       time.Since(start),
+		Options:    request.Options,
+		Request: model.Request{
+			Url:      request.Url,
+			Method:   http.MethodGet,
+			Headers:  headers,
+			Protocol: model.PROTOCOL_WEBSOCKET,
+		},
+		Events: events,
+		Frames: frames,
+	}, nil
+}
+
+func toWebSocketUrl(rawUrl string) (*url.URL, error) {
+	location, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+	switch location.Scheme {
+	case "ws", "wss":
+	case "http":
+		location.Scheme = "ws"
+	case "https":
+		location.Scheme = "wss"
+	default:
+		return nil, fmt.Errorf("unsupported websocket url scheme %s", location.Scheme)
+	}
+	return location, nil
+}
+
+func compileOptional(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) == 0 {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid websocket pattern %s: %w", pattern, err)
+	}
+	return re, nil
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func TestDoWebSocket(t *testing.T) {
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		websocket.Message.Send(ws, "hello "+ws.Request().Header.Get("X-Name"))
+		for {
+			var data string
+			if err := websocket.Message.Receive(ws, &data); err != nil {
+				return
+			}
+			websocket.Message.Send(ws, "echo: "+data)
+			if data == "bye" {
+				websocket.Message.Send(ws, "done")
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		url            string
+		messages       []model.WebSocketMessage
+		until          string
+		expectedBody   []string
+		expectedEvents int
+		expectErr      bool
+	}{
+		{
+			name: "Send messages until done",
+			url:  strings.Replace(server.URL, "http", "ws", 1),
+			messages: []model.WebSocketMessage{
+				{Send: "first", Await: "^echo: first$"},
+				{Send: "bye"},
+			},
+			until:          "^done$",
+			expectedBody:   []string{"> first", "< hello startpoint", "< echo: first", "> bye", "< echo: bye", "< done"},
+			expectedEvents: 4,
+		},
+		{
+			name:           "Http url is converted and server closing ends receiving",
+			url:            server.URL,
+			messages:       []model.WebSocketMessage{{Send: "bye"}},
+			expectedBody:   []string{"> bye", "< hello startpoint", "< echo: bye", "< done"},
+			expectedEvents: 3,
+		},
+		{
+			name:      "Invalid pattern",
+			url:       server.URL,
+			until:     "(",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received []string
+			resp, err := DoWebSocket(model.Request{
+				Url:      tt.url,
+				Headers:  model.Headers{"X-Name": {"startpoint"}},
+				Options:  map[string]interface{}{"websocket.timeoutSeconds": 5},
+				Protocol: model.PROTOCOL_WEBSOCKET,
+				Messages: tt.messages,
+				Until:    tt.until,
+				OnEvent: func(event model.Event) {
+					received = append(received, event.Data)
+				},
+			})
+			if tt.expectErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+			assert.ElementsMatch(t, tt.expectedBody, strings.Split(string(resp.Body), "\n"))
+			assert.Equal(t, tt.expectedEvents, len(received))
+			assert.Equal(t, tt.expectedEvents, len(resp.Events))
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package configuration
 
 import (
+	"fmt"
+	"math/big"
+
 	"github.com/rs/zerolog/log"
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 	conv "github.com/susiteemu/startpoint/core/tools/conv"
 )
@@ -106,10 +110,17 @@ func (c *Configuration) GetSliceMapString(key string) ([]map[string]string, bool
 	return nil, false
 }
 
+// GetInt reads an integer. Value may be of any numeric type or a string, e.g. Lua scripts
+// produce float64 and Starlark scripts *big.Int values.
 func (c *Configuration) GetInt(key string) (int, bool) {
 	value, has := c.requestOptions[key]
 	if has {
-		return value.(int), true
+		asInt, err := toInt(value)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Key %s has non-integer value %v", key, value)
+			return -1, false
+		}
+		return asInt, true
 	}
 	if viper.IsSet(key) {
 		return viper.GetInt(key), true
@@ -117,6 +128,43 @@ func (c *Configuration) GetInt(key string) (int, bool) {
 	return -1, false
 }
 
+// GetIntSlice reads a list of integers. A single value is read as a list of one. Values may be
+// of any type GetInt accepts.
+func (c *Configuration) GetIntSlice(key string) ([]int, bool) {
+	value, has := c.requestOptions[key]
+	if has {
+		values, isList := value.([]interface{})
+		if !isList {
+			values = []interface{}{value}
+		}
+		intSlice := []int{}
+		for _, v := range values {
+			asInt, err := toInt(v)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Key %s has non-integer value %v", key, v)
+				continue
+			}
+			intSlice = append(intSlice, asInt)
+		}
+		return intSlice, true
+	}
+	if viper.IsSet(key) {
+		return viper.GetIntSlice(key), true
+	}
+	return []int{}, false
+}
+
+// toInt converts value to int. Starlark integers are not known to cast.
+func toInt(value interface{}) (int, error) {
+	if bigInt, ok := value.(*big.Int); ok {
+		if !bigInt.IsInt64() {
+			return 0, fmt.Errorf("%v is too large for an integer", bigInt)
+		}
+		return int(bigInt.Int64()), nil
+	}
+	return cast.ToIntE(value)
+}
+
 func (c *Configuration) GetBool(key ...string) bool {
 	for _, k := range key {
 		value, has := c.requestOptions[k]
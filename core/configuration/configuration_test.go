@@ -2,6 +2,7 @@ package configuration
 
 import (
 	"fmt"
+	"math/big"
 	"reflect"
 	"slices"
 	"testing"
@@ -64,3 +65,58 @@ func TestBuildRequestYaml(t *testing.T) {
 		}
 	}
 }
+
+func TestGetIntSlice(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		wanted []int
+	}{
+		{"mixed", []interface{}{502, "503", "foo"}, []int{502, 503}},
+		{"lua numbers", []interface{}{float64(502), float64(503)}, []int{502, 503}},
+		{"starlark numbers", []interface{}{big.NewInt(502), big.NewInt(503)}, []int{502, 503}},
+		{"too large starlark number", []interface{}{new(big.Int).Lsh(big.NewInt(1), 70), big.NewInt(503)}, []int{503}},
+		{"scalar", 503, []int{503}},
+		{"scalar string", "503", []int{503}},
+	}
+	for _, tt := range tests {
+		c := NewWithRequestOptions(map[string]interface{}{
+			"httpClient.retry.onStatus": tt.value,
+		})
+		got, ok := c.GetIntSlice("httpClient.retry.onStatus")
+		if !ok || !slices.Equal(got, tt.wanted) {
+			t.Errorf("%s: got %v wanted %v\n", tt.name, got, tt.wanted)
+		}
+	}
+
+	c := NewWithRequestOptions(map[string]interface{}{})
+	_, ok := c.GetIntSlice("httpClient.retry.missing")
+	if ok {
+		t.Errorf("did not expect missing key to be set\n")
+	}
+}
+
+func TestGetInt(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		wanted int
+		set    bool
+	}{
+		{"int", 3, 3, true},
+		{"lua number", float64(3), 3, true},
+		{"starlark number", big.NewInt(3), 3, true},
+		{"string", "3", 3, true},
+		{"too large starlark number", new(big.Int).Lsh(big.NewInt(1), 70), -1, false},
+		{"not a number", "foo", -1, false},
+	}
+	for _, tt := range tests {
+		c := NewWithRequestOptions(map[string]interface{}{
+			"httpClient.retry.count": tt.value,
+		})
+		got, set := c.GetInt("httpClient.retry.count")
+		if got != tt.wanted || set != tt.set {
+			t.Errorf("%s: got %d, %v wanted %d, %v\n", tt.name, got, set, tt.wanted, tt.set)
+		}
+	}
+}
@@ -29,6 +29,34 @@ func OpenFileToEditorCmd(filepath string) (*exec.Cmd, error) {
 	return cmd, nil
 }
 
+// OpenFilesToPagerCmd opens files to $PAGER or, when it is not set, to the configured editor
+func OpenFilesToPagerCmd(filepaths ...string) (*exec.Cmd, error) {
+	if len(filepaths) == 0 {
+		return nil, errors.New("filepaths must not be empty.")
+	}
+
+	pager := strings.Fields(os.Getenv("PAGER"))
+	var args []string
+	if len(pager) > 0 {
+		args = pager[1:]
+	} else {
+		editor, editorArgs, err := getEditor()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to get pager or editor")
+			return nil, err
+		}
+		pager = []string{editor}
+		args = editorArgs
+	}
+	args = append(args, filepaths...)
+	log.Debug().Msgf("Using %s as pager with args %v", pager[0], args)
+	cmd := exec.Command(pager[0], args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd, nil
+}
+
 func getEditor() (string, []string, error) {
 	editor := strings.Fields(viper.GetString("editor"))
 	if len(editor) == 1 {
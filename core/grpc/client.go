@@ -0,0 +1,203 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var codeNames = []string{
+	"OK",
+	"CANCELLED",
+	"UNKNOWN",
+	"INVALID_ARGUMENT",
+	"DEADLINE_EXCEEDED",
+	"NOT_FOUND",
+	"ALREADY_EXISTS",
+	"PERMISSION_DENIED",
+	"RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION",
+	"ABORTED",
+	"OUT_OF_RANGE",
+	"UNIMPLEMENTED",
+	"INTERNAL",
+	"UNAVAILABLE",
+	"DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+// httpStatuses maps status codes to their closest http equivalent
+var httpStatuses = []int{200, 499, 500, 400, 504, 404, 409, 403, 429, 400, 409, 400, 501, 500, 503, 500, 401}
+
+// CodeName returns name of the code like NOT_FOUND
+func CodeName(code codes.Code) string {
+	if int(code) < len(codeNames) {
+		return codeNames[code]
+	}
+	return "UNKNOWN"
+}
+
+// HttpStatus returns http status code closest to the grpc status code
+func HttpStatus(code codes.Code) int {
+	if int(code) < len(httpStatuses) {
+		return httpStatuses[code]
+	}
+	return http.StatusInternalServerError
+}
+
+// Connection is a connection to a grpc server. It is safe for concurrent calls and should be
+// reused for calls to the same target.
+type Connection struct {
+	conn *grpc.ClientConn
+}
+
+// Dial creates a connection to target which is an url with scheme grpc or http for plaintext,
+// grpcs or https for tls, or just host:port for plaintext. Connections are opened lazily.
+func Dial(target string, tlsConfig *tls.Config) (*Connection, error) {
+	if !strings.Contains(target, "://") {
+		target = "grpc://" + target
+	}
+	location, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	var transportCredentials credentials.TransportCredentials
+	switch location.Scheme {
+	case "grpc", "http":
+		transportCredentials = insecure.NewCredentials()
+	case "grpcs", "https":
+		config := &tls.Config{}
+		if tlsConfig != nil {
+			config = tlsConfig.Clone()
+		}
+		transportCredentials = credentials.NewTLS(config)
+	default:
+		return nil, fmt.Errorf("unsupported grpc url scheme %s", location.Scheme)
+	}
+
+	conn, err := grpc.NewClient(location.Host, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		return nil, err
+	}
+	return &Connection{conn: conn}, nil
+}
+
+func (c *Connection) Close() error {
+	return c.conn.Close()
+}
+
+// Result of a call. Messages are the response messages as JSON.
+type Result struct {
+	Messages [][]byte
+	Header   http.Header
+	Trailer  http.Header
+	Status   *status.Status
+}
+
+// Invoke calls method sending messages, given as JSON, and then half-closing the stream. All
+// response messages are read until the server ends the call. Non-OK statuses are returned in
+// the result.
+func (c *Connection) Invoke(ctx context.Context, registry *Registry, method protoreflect.MethodDescriptor, header http.Header, messages [][]byte) (*Result, error) {
+	var requests []proto.Message
+	for _, message := range messages {
+		request, err := registry.Unmarshal(method.Input(), message)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s message: %w", method.Input().FullName(), err)
+		}
+		requests = append(requests, request)
+	}
+
+	fullMethod := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+	log.Debug().Msgf("Calling grpc method %s of %s", fullMethod, c.conn.Target())
+	ctx = metadata.NewOutgoingContext(ctx, toMetadata(header))
+	desc := &grpc.StreamDesc{
+		StreamName:    string(method.Name()),
+		ClientStreams: method.IsStreamingClient(),
+		ServerStreams: method.IsStreamingServer(),
+	}
+
+	stream, err := c.conn.NewStream(ctx, desc, fullMethod)
+	if err == nil {
+		err = send(stream, requests)
+		if errors.Is(err, io.EOF) {
+			// server ended the call early, its status is read with the responses
+			err = nil
+		}
+	}
+	result := &Result{}
+	for err == nil {
+		response := dynamicpb.NewMessage(method.Output())
+		err = stream.RecvMsg(response)
+		if err != nil {
+			break
+		}
+		asJson, marshalErr := registry.Marshal(response)
+		if marshalErr != nil {
+			return nil, fmt.Errorf("invalid %s message: %w", method.Output().FullName(), marshalErr)
+		}
+		result.Messages = append(result.Messages, asJson)
+		if !desc.ServerStreams {
+			// the only response has been read
+			err = io.EOF
+		}
+	}
+	if stream != nil {
+		header, _ := stream.Header()
+		result.Header = toHeader(header)
+		result.Trailer = toHeader(stream.Trailer())
+	}
+
+	if errors.Is(err, io.EOF) {
+		result.Status = status.New(codes.OK, "")
+		return result, nil
+	}
+	s, isStatus := status.FromError(err)
+	if !isStatus {
+		return nil, err
+	}
+	result.Status = s
+	return result, nil
+}
+
+func send(stream grpc.ClientStream, requests []proto.Message) error {
+	for _, request := range requests {
+		err := stream.SendMsg(request)
+		if err != nil {
+			return err
+		}
+	}
+	return stream.CloseSend()
+}
+
+func toMetadata(header http.Header) metadata.MD {
+	md := metadata.MD{}
+	for name, values := range header {
+		md.Append(name, values...)
+	}
+	return md
+}
+
+func toHeader(md metadata.MD) http.Header {
+	header := http.Header{}
+	for name, values := range md {
+		header[http.CanonicalHeaderKey(name)] = values
+	}
+	return header
+}
@@ -0,0 +1,176 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func parseGreeter(t *testing.T) *Registry {
+	registry, err := ParseFiles([]string{"testdata/hello.proto"}, nil)
+	assert.NoError(t, err)
+	return registry
+}
+
+// newGreeterServer serves test.Greeter using dynamic messages. Reflection is served with
+// version v1 or v1alpha.
+func newGreeterServer(t *testing.T, reflectionV1 bool) string {
+	registry := parseGreeter(t)
+	method, err := registry.FindMethod("test.Greeter", "SayHello")
+	assert.NoError(t, err)
+	input, output := method.Input(), method.Output()
+	reply := func(request *dynamicpb.Message, greeting string) *dynamicpb.Message {
+		name := request.Get(input.Fields().ByName("name")).String()
+		message := dynamicpb.NewMessage(output)
+		message.Set(output.Fields().ByName("message"), protoreflect.ValueOfString(greeting+" "+name))
+		return message
+	}
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Greeter",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "SayHello",
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				md, _ := metadata.FromIncomingContext(ctx)
+				if token := md.Get("x-token"); len(token) == 0 || token[0] != "secret" {
+					return nil, status.Error(codes.Unauthenticated, "missing token")
+				}
+				request := dynamicpb.NewMessage(input)
+				if err := dec(request); err != nil {
+					return nil, err
+				}
+				grpc.SetHeader(ctx, metadata.Pairs("x-greeter", "test"))
+				return reply(request, "Hello"), nil
+			},
+		}},
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "SayHellos",
+			ServerStreams: true,
+			Handler: func(_ interface{}, stream grpc.ServerStream) error {
+				request := dynamicpb.NewMessage(input)
+				if err := stream.RecvMsg(request); err != nil {
+					return err
+				}
+				for _, greeting := range []string{"Hello", "Hi"} {
+					if err := stream.SendMsg(reply(request, greeting)); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}},
+	}, struct{}{})
+
+	options := reflection.ServerOptions{
+		Services:           server,
+		DescriptorResolver: registry.files,
+		ExtensionResolver:  &protoregistry.Types{},
+	}
+	if reflectionV1 {
+		reflectionv1.RegisterServerReflectionServer(server, reflection.NewServerV1(options))
+	} else {
+		reflectionv1alpha.RegisterServerReflectionServer(server, reflection.NewServer(options))
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return "grpc://" + listener.Addr().String()
+}
+
+func TestParseFiles(t *testing.T) {
+	registry := parseGreeter(t)
+
+	method, err := registry.FindMethod(".test.Greeter", "SayHellos")
+	assert.NoError(t, err)
+	assert.Equal(t, protoreflect.FullName("test.HelloRequest"), method.Input().FullName())
+	assert.True(t, method.IsStreamingServer())
+
+	// well-known types are built in
+	message, err := registry.Unmarshal(method.Input(), []byte(`{"name": "world", "at": "2024-01-02T03:04:05Z"}`))
+	assert.NoError(t, err)
+	asJson, err := registry.Marshal(message)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"world","at":"2024-01-02T03:04:05Z"}`, string(asJson))
+
+	_, err = registry.FindMethod("test.Greeter", "Missing")
+	assert.Error(t, err)
+	_, err = registry.FindMethod("test.Missing", "SayHello")
+	assert.Error(t, err)
+	_, err = ParseFiles([]string{"testdata/missing.proto"}, nil)
+	assert.Error(t, err)
+}
+
+func TestInvoke(t *testing.T) {
+	target := newGreeterServer(t, true)
+	registry := parseGreeter(t)
+
+	conn, err := Dial(target, nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	method, _ := registry.FindMethod("test.Greeter", "SayHello")
+	result, err := conn.Invoke(context.Background(), registry, method, http.Header{"X-Token": {"secret"}}, [][]byte{[]byte(`{"name": "world"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, codes.OK, result.Status.Code())
+	assert.Equal(t, [][]byte{[]byte(`{"message":"Hello world"}`)}, result.Messages)
+	assert.Equal(t, []string{"test"}, result.Header["X-Greeter"])
+
+	result, err = conn.Invoke(context.Background(), registry, method, nil, [][]byte{[]byte(`{"name": "world"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, codes.Unauthenticated, result.Status.Code())
+	assert.Equal(t, "missing token", result.Status.Message())
+	assert.Empty(t, result.Messages)
+
+	method, _ = registry.FindMethod("test.Greeter", "SayHellos")
+	result, err = conn.Invoke(context.Background(), registry, method, nil, [][]byte{[]byte(`{"name": "world"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, codes.OK, result.Status.Code())
+	assert.Equal(t, [][]byte{[]byte(`{"message":"Hello world"}`), []byte(`{"message":"Hi world"}`)}, result.Messages)
+
+	method, _ = registry.FindMethod("test.Greeter", "SayGoodbye")
+	result, err = conn.Invoke(context.Background(), registry, method, nil, [][]byte{[]byte(`{}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, codes.Unimplemented, result.Status.Code())
+	assert.Equal(t, "UNIMPLEMENTED", CodeName(result.Status.Code()))
+	assert.Equal(t, http.StatusNotImplemented, HttpStatus(result.Status.Code()))
+
+	_, err = conn.Invoke(context.Background(), registry, method, nil, [][]byte{[]byte(`{"unknown": 1}`)})
+	assert.Error(t, err)
+}
+
+func TestReflect(t *testing.T) {
+	for _, reflectionV1 := range []bool{true, false} {
+		target := newGreeterServer(t, reflectionV1)
+
+		conn, err := Dial(target, nil)
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		registry, err := Reflect(context.Background(), conn, nil, "test.Greeter")
+		assert.NoError(t, err)
+		method, err := registry.FindMethod("test.Greeter", "SayHello")
+		assert.NoError(t, err)
+		assert.Equal(t, protoreflect.FullName("test.HelloRequest"), method.Input().FullName())
+		assert.Equal(t, protoreflect.FullName("test.HelloReply"), method.Output().FullName())
+
+		_, err = Reflect(context.Background(), conn, nil, "test.Missing")
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,150 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// standardImports resolves well-known types such as google/protobuf/timestamp.proto
+var standardImports = protocompile.WithStandardImports(protocompile.ResolverFunc(func(string) (protocompile.SearchResult, error) {
+	return protocompile.SearchResult{}, os.ErrNotExist
+}))
+
+// fetchFiles returns serialized file descriptors of the file containing symbol or, when symbol
+// is empty, of the named file. The server may include dependencies of the file.
+type fetchFiles func(symbol, fileName string) ([][]byte, error)
+
+// Reflect resolves the service and the types it depends on using server reflection. Version v1
+// of the reflection service is tried first and v1alpha after it.
+func Reflect(ctx context.Context, conn *Connection, header http.Header, service string) (*Registry, error) {
+	ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(ctx, toMetadata(header)))
+	defer cancel()
+
+	service = strings.TrimPrefix(service, ".")
+	fetch, err := reflectV1(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	descriptors, err := fetch(service, "")
+	if status.Code(err) == codes.Unimplemented {
+		log.Debug().Msg("Server does not implement reflection v1, trying v1alpha")
+		fetch, err = reflectV1Alpha(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		descriptors, err = fetch(service, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fileSet := &descriptorpb.FileDescriptorSet{}
+	seen := map[string]bool{}
+	for len(descriptors) > 0 {
+		var missing []string
+		for _, descriptor := range descriptors {
+			file := &descriptorpb.FileDescriptorProto{}
+			err = proto.Unmarshal(descriptor, file)
+			if err != nil {
+				return nil, err
+			}
+			if seen[file.GetName()] {
+				continue
+			}
+			seen[file.GetName()] = true
+			fileSet.File = append(fileSet.File, file)
+			missing = append(missing, file.GetDependency()...)
+		}
+		descriptors = nil
+		for _, dependency := range missing {
+			if seen[dependency] {
+				continue
+			}
+			fetched, err := fetch("", dependency)
+			if err != nil {
+				// well-known types are not always served
+				builtin, findErr := standardImports.FindFileByPath(dependency)
+				if findErr != nil || builtin.Desc == nil {
+					return nil, err
+				}
+				seen[dependency] = true
+				fileSet.File = append(fileSet.File, protodesc.ToFileDescriptorProto(builtin.Desc))
+				continue
+			}
+			descriptors = append(descriptors, fetched...)
+		}
+	}
+
+	files, err := protodesc.NewFiles(fileSet)
+	if err != nil {
+		return nil, err
+	}
+	return newRegistry(files), nil
+}
+
+func reflectV1(ctx context.Context, conn *Connection) (fetchFiles, error) {
+	stream, err := reflectionv1.NewServerReflectionClient(conn.conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(symbol, fileName string) ([][]byte, error) {
+		request := &reflectionv1.ServerReflectionRequest{}
+		if len(symbol) > 0 {
+			request.MessageRequest = &reflectionv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol}
+		} else {
+			request.MessageRequest = &reflectionv1.ServerReflectionRequest_FileByFilename{FileByFilename: fileName}
+		}
+		err := stream.Send(request)
+		if err != nil {
+			return nil, err
+		}
+		response, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if errorResponse := response.GetErrorResponse(); errorResponse != nil {
+			return nil, status.Error(codes.Code(errorResponse.GetErrorCode()), errorResponse.GetErrorMessage())
+		}
+		return response.GetFileDescriptorResponse().GetFileDescriptorProto(), nil
+	}, nil
+}
+
+func reflectV1Alpha(ctx context.Context, conn *Connection) (fetchFiles, error) {
+	stream, err := reflectionv1alpha.NewServerReflectionClient(conn.conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(symbol, fileName string) ([][]byte, error) {
+		request := &reflectionv1alpha.ServerReflectionRequest{}
+		if len(symbol) > 0 {
+			request.MessageRequest = &reflectionv1alpha.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol}
+		} else {
+			request.MessageRequest = &reflectionv1alpha.ServerReflectionRequest_FileByFilename{FileByFilename: fileName}
+		}
+		err := stream.Send(request)
+		if err != nil {
+			return nil, err
+		}
+		response, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if errorResponse := response.GetErrorResponse(); errorResponse != nil {
+			return nil, status.Error(codes.Code(errorResponse.GetErrorCode()), errorResponse.GetErrorMessage())
+		}
+		return response.GetFileDescriptorResponse().GetFileDescriptorProto(), nil
+	}, nil
+}
@@ -0,0 +1,128 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Registry holds the descriptors of services and their messages, either parsed from proto
+// files or resolved using server reflection
+type Registry struct {
+	files *protoregistry.Files
+	types *dynamicpb.Types
+}
+
+func newRegistry(files *protoregistry.Files) *Registry {
+	return &Registry{
+		files: files,
+		types: dynamicpb.NewTypes(files),
+	}
+}
+
+// ParseFiles compiles proto files. Imports are searched from importPaths and the directories of
+// the files. Well-known types such as google/protobuf/timestamp.proto are built in.
+func ParseFiles(protoFiles []string, importPaths []string) (*Registry, error) {
+	searchPaths := append([]string{}, importPaths...)
+	for _, file := range protoFiles {
+		searchPaths = append(searchPaths, filepath.Dir(file))
+	}
+	var names []string
+	for _, file := range protoFiles {
+		names = append(names, importName(file, searchPaths))
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: searchPaths}),
+	}
+	compiled, err := compiler.Compile(context.Background(), names...)
+	if err != nil {
+		return nil, err
+	}
+	files := &protoregistry.Files{}
+	for _, file := range compiled {
+		err = registerFile(files, file)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newRegistry(files), nil
+}
+
+// importName returns name of file relative to the first search path containing it
+func importName(file string, searchPaths []string) string {
+	for _, path := range searchPaths {
+		name, err := filepath.Rel(path, file)
+		if err == nil && !strings.HasPrefix(name, "..") {
+			return filepath.ToSlash(name)
+		}
+	}
+	return file
+}
+
+// registerFile registers file after its imports
+func registerFile(files *protoregistry.Files, file protoreflect.FileDescriptor) error {
+	if _, err := files.FindFileByPath(file.Path()); err == nil {
+		return nil
+	}
+	imports := file.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		err := registerFile(files, imports.Get(i).FileDescriptor)
+		if err != nil {
+			return err
+		}
+	}
+	return files.RegisterFile(file)
+}
+
+// FindMethod returns descriptor of a method of the fully qualified service
+func (r *Registry) FindMethod(service, method string) (protoreflect.MethodDescriptor, error) {
+	descriptor, err := r.files.FindDescriptorByName(protoreflect.FullName(strings.TrimPrefix(service, ".")))
+	if err != nil {
+		return nil, fmt.Errorf("unknown grpc service %s", service)
+	}
+	serviceDescriptor, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a grpc service", service)
+	}
+	methodDescriptor := serviceDescriptor.Methods().ByName(protoreflect.Name(method))
+	if methodDescriptor == nil {
+		return nil, fmt.Errorf("grpc service %s has no method %s", service, method)
+	}
+	return methodDescriptor, nil
+}
+
+// Unmarshal reads a message of the given type from its proto3 JSON form
+func (r *Registry) Unmarshal(message protoreflect.MessageDescriptor, body []byte) (proto.Message, error) {
+	decoded := dynamicpb.NewMessage(message)
+	err := protojson.UnmarshalOptions{Resolver: r.types}.Unmarshal(body, decoded)
+	if err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// Marshal writes a message in its proto3 JSON form
+func (r *Registry) Marshal(message proto.Message) ([]byte, error) {
+	encoded, err := protojson.MarshalOptions{Resolver: r.types}.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	// protojson varies its whitespace on purpose so compact it for stable output
+	var compacted bytes.Buffer
+	err = json.Compact(&compacted, encoded)
+	if err != nil {
+		return nil, err
+	}
+	return compacted.Bytes(), nil
+}
@@ -0,0 +1,100 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/tools/paths"
+	"github.com/susiteemu/startpoint/core/writer"
+
+	"github.com/rs/zerolog/log"
+)
+
+const HISTORY_DIR = ".startpoint/history"
+
+// saveMu serializes writing entries so that concurrent runs of a request do not write the same
+// file at the same time
+var saveMu sync.Mutex
+
+type Entry struct {
+	RequestName string              `json:"requestName"`
+	Method      string              `json:"method"`
+	Url         string              `json:"url"`
+	StatusCode  int                 `json:"statusCode"`
+	Headers     map[string][]string `json:"headers"`
+	Body        string              `json:"body"`
+	ReceivedAt  time.Time           `json:"receivedAt"`
+}
+
+// Save stores the response as the latest history entry of the request.
+func Save(root string, resp *model.Response) error {
+	if resp == nil {
+		return errors.New("response must not be nil")
+	}
+	if len(resp.RequestName) == 0 {
+		return errors.New("response must have a request name")
+	}
+
+	body, err := resp.FullBody()
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to read body of %s from %s", resp.RequestName, resp.BodyFile)
+		return err
+	}
+
+	entry := Entry{
+		RequestName: resp.RequestName,
+		Method:      resp.Request.Method,
+		Url:         resp.Request.Url,
+		StatusCode:  resp.StatusCode,
+		Headers:     resp.HeadersAsMapString(),
+		Body:        string(body),
+		ReceivedAt:  resp.ReceivedAt,
+	}
+
+	contents, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(root, HISTORY_DIR)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to create history directory %s", dir)
+		return err
+	}
+
+	saveMu.Lock()
+	defer saveMu.Unlock()
+	_, err = writer.WriteFile(entryPath(root, resp.RequestName), string(contents))
+	return err
+}
+
+// Latest reads the latest history entry of the request. Returns nil without
+// error if the request has no recorded history.
+func Latest(root, requestName string) (*Entry, error) {
+	file, err := os.ReadFile(entryPath(root, requestName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entry := &Entry{}
+	err = json.Unmarshal(file, entry)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to unmarshal history entry of %s", requestName)
+		return nil, err
+	}
+	return entry, nil
+}
+
+func entryPath(root, requestName string) string {
+	// request names come from file names and the command line so keep entries inside the
+	// history directory
+	return filepath.Join(root, HISTORY_DIR, paths.SanitizeFileName(requestName)+".json")
+}
@@ -0,0 +1,65 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveAndLatest(t *testing.T) {
+	root := t.TempDir()
+	receivedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	resp := &model.Response{
+		Headers:     map[string]model.HeaderValues{"Content-Type": {"application/json"}},
+		Body:        []byte(`{"id": 1}`),
+		StatusCode:  200,
+		ReceivedAt:  receivedAt,
+		Request:     model.Request{Method: "GET", Url: "http://localhost:8000/pets/1"},
+		RequestName: "pet",
+	}
+
+	assert.Nil(t, Save(root, resp))
+
+	entry, err := Latest(root, "pet")
+	assert.Nil(t, err)
+	assert.Equal(t, &Entry{
+		RequestName: "pet",
+		Method:      "GET",
+		Url:         "http://localhost:8000/pets/1",
+		StatusCode:  200,
+		Headers:     map[string][]string{"Content-Type": {"application/json"}},
+		Body:        `{"id": 1}`,
+		ReceivedAt:  receivedAt,
+	}, entry)
+}
+
+func TestLatestWithoutHistory(t *testing.T) {
+	entry, err := Latest(t.TempDir(), "pet")
+	assert.Nil(t, err)
+	assert.Nil(t, entry)
+}
+
+func TestSaveKeepsEntryInsideHistoryDir(t *testing.T) {
+	root := t.TempDir()
+	resp := &model.Response{
+		Body:        []byte("ok"),
+		StatusCode:  200,
+		RequestName: "../../pets/1",
+	}
+
+	assert.Nil(t, Save(root, resp))
+
+	entries, err := os.ReadDir(filepath.Join(root, HISTORY_DIR))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, ".._.._pets_1.json", entries[0].Name())
+
+	entry, err := Latest(root, "../../pets/1")
+	assert.Nil(t, err)
+	assert.Equal(t, "../../pets/1", entry.RequestName)
+}
@@ -0,0 +1,347 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/susiteemu/startpoint/core/client/validator"
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/tools/paths"
+	"github.com/susiteemu/startpoint/core/writer"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    types {
+      kind
+      name
+      fields(includeDeprecated: true) {
+        name
+        args { name type { ...TypeRef } }
+        type { ...TypeRef }
+      }
+      inputFields { name type { ...TypeRef } }
+      enumValues(includeDeprecated: true) { name }
+    }
+  }
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } }
+}`
+
+// maxSelectionDepth limits how deep into nested objects generated queries select fields
+const maxSelectionDepth = 3
+
+// ReadSchema introspects the graphql endpoint at location, or reads an introspection result
+// from a file, and writes a .graphql request of each query and mutation to workspace
+func ReadSchema(location string, headers map[string]string, workspace string) error {
+	schemaBytes, endpoint, err := loadSchema(location, headers)
+	if err != nil {
+		return err
+	}
+
+	requests, err := ImportSchema(schemaBytes, endpoint, workspace)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read schema")
+		return err
+	}
+
+	fmt.Printf("Processed schema into %d requests. Next going to save these.\n\n", len(requests))
+
+	for _, request := range requests {
+		path := filepath.Join(request.Root, request.Filename)
+		contents := request.Raw()
+		_, err := writer.WriteFile(path, contents)
+		status := "OK"
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to save request")
+			status = "ERROR"
+		}
+		fmt.Printf("[%s] %s\n", status, path)
+	}
+	return nil
+}
+
+func loadSchema(location string, headers map[string]string) ([]byte, string, error) {
+	if validator.IsValidUrl(location) {
+		fmt.Print("Given location seems to be an URL. Proceeding to introspect it...")
+		body, _ := json.Marshal(map[string]string{"query": IntrospectionQuery})
+		resp, err := resty.New().R().
+			SetHeaders(headers).
+			SetHeader(model.HEADER_NAME_CONTENT_TYPE, model.CONTENT_TYPE_APPLICATION_JSON).
+			SetBody(body).
+			Post(location)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to introspect %s", location)
+			fmt.Print("FAILED.\n")
+			return nil, "", err
+		}
+		if !resp.IsSuccess() {
+			fmt.Print("FAILED.\n")
+			return nil, "", fmt.Errorf("introspection failed with status %s", resp.Status())
+		}
+		fmt.Print("DONE\n")
+		return resp.Body(), location, nil
+	}
+
+	fmt.Print("Given location seems to be a local file. Proceeding to read it...")
+	file, err := os.ReadFile(location)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to read %s", location)
+		fmt.Print("FAILED.\n")
+		return nil, "", err
+	}
+	fmt.Print("DONE\n")
+	return file, "", nil
+}
+
+type typeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *typeRef `json:"ofType"`
+}
+
+func (t typeRef) String() string {
+	switch {
+	case t.Kind == "NON_NULL" && t.OfType != nil:
+		return t.OfType.String() + "!"
+	case t.Kind == "LIST" && t.OfType != nil:
+		return "[" + t.OfType.String() + "]"
+	}
+	return t.Name
+}
+
+func (t typeRef) named() typeRef {
+	if t.OfType != nil && (t.Kind == "NON_NULL" || t.Kind == "LIST") {
+		return t.OfType.named()
+	}
+	return t
+}
+
+type inputValue struct {
+	Name string  `json:"name"`
+	Type typeRef `json:"type"`
+}
+
+type field struct {
+	Name string       `json:"name"`
+	Args []inputValue `json:"args"`
+	Type typeRef      `json:"type"`
+}
+
+type fullType struct {
+	Kind        string       `json:"kind"`
+	Name        string       `json:"name"`
+	Fields      []field      `json:"fields"`
+	InputFields []inputValue `json:"inputFields"`
+	EnumValues  []struct {
+		Name string `json:"name"`
+	} `json:"enumValues"`
+}
+
+type schema struct {
+	QueryType *struct {
+		Name string `json:"name"`
+	} `json:"queryType"`
+	MutationType *struct {
+		Name string `json:"name"`
+	} `json:"mutationType"`
+	Types []fullType `json:"types"`
+}
+
+type operationType struct {
+	operation string
+	typeName  string
+}
+
+// ImportSchema generates requests from an introspection result. Requests are sent to endpoint
+// or to {url} when it is empty.
+func ImportSchema(introspection []byte, endpoint, workspace string) ([]model.RequestMold, error) {
+	var result struct {
+		Data struct {
+			Schema *schema `json:"__schema"`
+		} `json:"data"`
+		Schema *schema `json:"__schema"`
+	}
+	if err := json.Unmarshal(introspection, &result); err != nil {
+		return nil, err
+	}
+	s := result.Data.Schema
+	if s == nil {
+		s = result.Schema
+	}
+	if s == nil {
+		return nil, errors.New("introspection result does not contain a schema")
+	}
+
+	types := map[string]fullType{}
+	for _, t := range s.Types {
+		types[t.Name] = t
+	}
+	if endpoint == "" {
+		endpoint = "{url}"
+	}
+
+	var requests []model.RequestMold
+	names := map[string]bool{}
+	var operations []operationType
+	if s.QueryType != nil {
+		operations = append(operations, operationType{"query", s.QueryType.Name})
+	}
+	if s.MutationType != nil {
+		operations = append(operations, operationType{"mutation", s.MutationType.Name})
+	}
+	for _, op := range operations {
+		for _, f := range types[op.typeName].Fields {
+			name := f.Name
+			if names[name] {
+				name = fmt.Sprintf("%s_%s", name, op.operation)
+			}
+			names[name] = true
+
+			query, variables := generateOperation(op.operation, f, types)
+			yamlRequest := model.YamlRequest{
+				Url: endpoint,
+				GraphQL: &model.GraphQLRequest{
+					Query:     query,
+					Variables: variables,
+				},
+			}
+			requests = append(requests, model.RequestMold{
+				Root:     workspace,
+				Filename: fmt.Sprintf("%s.graphql", paths.SanitizeFileName(name)),
+				Type:     model.CONTENT_TYPE_GRAPHQL,
+				Name:     name,
+				Yaml:     &yamlRequest,
+			})
+		}
+	}
+	return requests, nil
+}
+
+func generateOperation(operation string, f field, types map[string]fullType) (string, map[string]interface{}) {
+	var definitions, arguments []string
+	variables := map[string]interface{}{}
+	for _, arg := range f.Args {
+		definitions = append(definitions, fmt.Sprintf("$%s: %s", arg.Name, arg.Type))
+		arguments = append(arguments, fmt.Sprintf("%s: $%s", arg.Name, arg.Name))
+		var example interface{}
+		if arg.Type.Kind == "NON_NULL" {
+			example = exampleValue(arg.Type, types, 0)
+		}
+		variables[arg.Name] = example
+	}
+
+	var query strings.Builder
+	query.WriteString(operation + " " + f.Name)
+	if len(definitions) > 0 {
+		query.WriteString("(" + strings.Join(definitions, ", ") + ")")
+	}
+	query.WriteString(" {\n  " + f.Name)
+	if len(arguments) > 0 {
+		query.WriteString("(" + strings.Join(arguments, ", ") + ")")
+	}
+	if selection := selectionSet(f.Type, types, 1, "  "); selection != "" {
+		query.WriteString(" " + selection)
+	}
+	query.WriteString("\n}\n")
+
+	if len(variables) == 0 {
+		variables = nil
+	}
+	return query.String(), variables
+}
+
+// selectionSet returns selection of scalar fields of the type and of its nested objects up to
+// maxSelectionDepth. Fields with required arguments are left out.
+func selectionSet(t typeRef, types map[string]fullType, depth int, indent string) string {
+	named := types[t.named().Name]
+	switch named.Kind {
+	case "OBJECT", "INTERFACE", "UNION":
+	default:
+		return ""
+	}
+
+	var lines []string
+	for _, f := range named.Fields {
+		if strings.HasPrefix(f.Name, "__") || hasRequiredArgs(f) {
+			continue
+		}
+		switch types[f.Type.named().Name].Kind {
+		case "OBJECT", "INTERFACE", "UNION":
+			if depth >= maxSelectionDepth {
+				continue
+			}
+			if nested := selectionSet(f.Type, types, depth+1, indent+"  "); nested != "" {
+				lines = append(lines, f.Name+" "+nested)
+			}
+		default:
+			lines = append(lines, f.Name)
+		}
+	}
+	if len(lines) == 0 {
+		if depth > 1 {
+			return ""
+		}
+		lines = []string{"__typename"}
+	}
+
+	inner := indent + "  "
+	return "{\n" + inner + strings.Join(lines, "\n"+inner) + "\n" + indent + "}"
+}
+
+func hasRequiredArgs(f field) bool {
+	for _, arg := range f.Args {
+		if arg.Type.Kind == "NON_NULL" {
+			return true
+		}
+	}
+	return false
+}
+
+func exampleValue(t typeRef, types map[string]fullType, depth int) interface{} {
+	switch t.Kind {
+	case "NON_NULL":
+		return exampleValue(*t.OfType, types, depth)
+	case "LIST":
+		return []interface{}{}
+	}
+	switch t.Name {
+	case "Int":
+		return 0
+	case "Float":
+		return 0.0
+	case "Boolean":
+		return false
+	}
+	named := types[t.Name]
+	switch named.Kind {
+	case "ENUM":
+		if len(named.EnumValues) > 0 {
+			return named.EnumValues[0].Name
+		}
+	case "INPUT_OBJECT":
+		object := map[string]interface{}{}
+		if depth < maxSelectionDepth {
+			for _, inputField := range named.InputFields {
+				if inputField.Type.Kind == "NON_NULL" {
+					object[inputField.Name] = exampleValue(inputField.Type, types, depth+1)
+				}
+			}
+		}
+		return object
+	}
+	return ""
+}
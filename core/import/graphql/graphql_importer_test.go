@@ -0,0 +1,160 @@
+package graphql
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/susiteemu/startpoint/core/loader"
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadGraphQLRequest(t *testing.T) {
+	request, err := loader.ReadRequest("testdata", "pet_by_id.graphql")
+	if err != nil {
+		t.Errorf("did not expect error %v", err)
+		return
+	}
+
+	assert.Equal(t, model.CONTENT_TYPE_GRAPHQL, request.Type)
+	assert.Equal(t, "testdata", request.Root)
+	assert.Equal(t, "pet_by_id.graphql", request.Filename)
+	assert.Equal(t, "pet_by_id", request.Name)
+	assert.True(t, request.IsGraphQL())
+	assert.Equal(t, "{domain}/graphql", request.Yaml.Url)
+	assert.Equal(t, model.Headers{"X-Api-Key": {"secret"}}, request.Yaml.Headers)
+	assert.Equal(t, &model.GraphQLRequest{
+		Query: `query PetById($id: ID!, $withOwner: Boolean!) {
+  pet(id: $id) {
+    name
+    owner @include(if: $withOwner) {
+      name
+    }
+  }
+}
+
+query Pets {
+  pets {
+    name
+  }
+}`,
+		Variables: map[string]interface{}{
+			"id":        1,
+			"withOwner": true,
+		},
+		OperationName: "PetById",
+	}, request.Yaml.GraphQL)
+}
+
+var wantedImportedRequests = []struct {
+	name      string
+	filename  string
+	query     string
+	variables map[string]interface{}
+}{
+	{
+		name:     "pet",
+		filename: "pet.graphql",
+		query: `query pet($id: ID!) {
+  pet(id: $id) {
+    id
+    name
+    owner {
+      name
+    }
+  }
+}
+`,
+		variables: map[string]interface{}{"id": ""},
+	},
+	{
+		name:     "pets",
+		filename: "pets.graphql",
+		query: `query pets($first: Int) {
+  pets(first: $first) {
+    id
+    name
+    owner {
+      name
+    }
+  }
+}
+`,
+		variables: map[string]interface{}{"first": nil},
+	},
+	{
+		name:     "addPet",
+		filename: "addPet.graphql",
+		query: `mutation addPet($input: PetInput!) {
+  addPet(input: $input) {
+    id
+    name
+    owner {
+      name
+    }
+  }
+}
+`,
+		variables: map[string]interface{}{
+			"input": map[string]interface{}{"name": "", "kind": "DOG"},
+		},
+	},
+}
+
+func TestImportSchema(t *testing.T) {
+	introspection, err := os.ReadFile("testdata/introspection.json")
+	if err != nil {
+		t.Errorf("did not expect error %v", err)
+		return
+	}
+
+	requests, err := ImportSchema(introspection, "", "workspace")
+	if err != nil {
+		t.Errorf("did not expect error %v", err)
+		return
+	}
+
+	assert.Equal(t, len(wantedImportedRequests), len(requests))
+	for i, wanted := range wantedImportedRequests {
+		request := requests[i]
+		assert.Equal(t, wanted.name, request.Name)
+		assert.Equal(t, wanted.filename, request.Filename)
+		assert.Equal(t, "workspace", request.Root)
+		assert.Equal(t, model.CONTENT_TYPE_GRAPHQL, request.Type)
+		assert.Equal(t, "{url}", request.Yaml.Url)
+		assert.Equal(t, wanted.query, request.Yaml.GraphQL.Query)
+		assert.Equal(t, wanted.variables, request.Yaml.GraphQL.Variables)
+	}
+}
+
+func TestImportSchemaWithoutSchema(t *testing.T) {
+	_, err := ImportSchema([]byte(`{"data": {}}`), "", "workspace")
+	assert.Error(t, err)
+}
+
+func TestReadSchemaWritesLoadableRequests(t *testing.T) {
+	workspace := t.TempDir()
+
+	err := ReadSchema("testdata/introspection.json", nil, workspace)
+	if err != nil {
+		t.Errorf("did not expect error %v", err)
+		return
+	}
+
+	for _, wanted := range wantedImportedRequests {
+		request, err := loader.ReadRequest(workspace, wanted.filename)
+		if err != nil {
+			t.Errorf("did not expect error %v", err)
+			return
+		}
+		assert.Equal(t, wanted.name, request.Name)
+		assert.Equal(t, model.CONTENT_TYPE_GRAPHQL, request.Type)
+		assert.Equal(t, "{url}", request.Yaml.Url)
+		assert.Equal(t, &model.GraphQLRequest{
+			Query:     strings.TrimSuffix(wanted.query, "\n"),
+			Variables: wanted.variables,
+		}, request.Yaml.GraphQL)
+	}
+}
@@ -3,6 +3,7 @@ package openapi
 import (
 	"fmt"
 	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/tools/paths"
 	"strings"
 
 	"github.com/pb33f/libopenapi"
@@ -27,20 +28,20 @@ func ImportOpenAPIV3(document libopenapi.Document, workspace string) ([]model.Re
 	}
 	fmt.Print("DONE\n")
 
-	paths := v3Model.Model.Paths.PathItems
+	pathItems := v3Model.Model.Paths.PathItems
 	schemas := v3Model.Model.Components.Schemas
 	servers := v3Model.Model.Servers
 	securitySchemes := v3Model.Model.Components.SecuritySchemes
 	jsonMockGenerator := renderer.NewMockGenerator(renderer.JSON)
 
-	fmt.Printf("\nThere are %d paths, %d schemas and %d servers in the document.\n", paths.Len(), schemas.Len(), len(servers))
+	fmt.Printf("\nThere are %d paths, %d schemas and %d servers in the document.\n", pathItems.Len(), schemas.Len(), len(servers))
 
 	fmt.Print("\n")
 
 	profiles := handleServersV3(servers, workspace)
 
 	requests := []model.RequestMold{}
-	for pathPairs := paths.First(); pathPairs != nil; pathPairs = pathPairs.Next() {
+	for pathPairs := pathItems.First(); pathPairs != nil; pathPairs = pathPairs.Next() {
 		pathItem := pathPairs.Value()
 		// TODO: handle server override from path
 		// operationValue.Servers
@@ -129,7 +130,7 @@ func ImportOpenAPIV3(document libopenapi.Document, workspace string) ([]model.Re
 				requestName = fmt.Sprintf("%s %s", yamlRequest.Method, pathPairs.Key())
 			}
 
-			filename := fmt.Sprintf("%s.yaml", sanitizeFileName(requestName))
+			filename := fmt.Sprintf("%s.yaml", paths.SanitizeFileName(requestName))
 
 			requestMold := model.RequestMold{
 				Root:     workspace,
@@ -8,7 +8,6 @@ import (
 	"github.com/susiteemu/startpoint/core/writer"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"github.com/go-resty/resty/v2"
@@ -135,17 +134,3 @@ func loadSpec(path string) ([]byte, error) {
 	}
 
 }
-
-func sanitizeFileName(fileName string) string {
-	// Define a regular expression to match invalid file name characters
-	reg := regexp.MustCompile(`[<>:"/\\|?*\x00-\x1F]`)
-
-	// Replace invalid characters with an underscore
-	safeFileName := reg.ReplaceAllString(fileName, "_")
-
-	// Additional replacement for Windows reserved names (optional)
-	reservedNames := regexp.MustCompile(`^(CON|PRN|AUX|NUL|COM\d|LPT\d)(\..*)?$`)
-	safeFileName = reservedNames.ReplaceAllString(safeFileName, "reserved_$1")
-
-	return safeFileName
-}
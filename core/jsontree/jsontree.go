@@ -0,0 +1,270 @@
+package jsontree
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vmware-labs/yaml-jsonpath/pkg/yamlpath"
+	"gopkg.in/yaml.v3"
+)
+
+const ROOT_PATH = "$"
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Node is a value of a json document with its children when it is an object or an array
+type Node struct {
+	// Key is the object key or the array index of the value, empty for the root
+	Key string
+	// Path is the JSONPath of the value
+	Path     string
+	Depth    int
+	Parent   *Node
+	Children []*Node
+	Expanded bool
+	value    *yaml.Node
+}
+
+// Parse reads json document into a tree. The document is decoded token by token into yaml nodes
+// to keep the order of object keys and to filter it with JSONPath.
+func Parse(document []byte) (*Node, *yaml.Node, error) {
+	if !json.Valid(document) {
+		return nil, nil, errors.New("body is not valid json")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	value, err := decodeValue(decoder)
+	if err != nil {
+		return nil, nil, err
+	}
+	root := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{value}}
+	tree := newNode("", ROOT_PATH, value, nil)
+	tree.Expanded = true
+	return tree, root, nil
+}
+
+func decodeValue(decoder *json.Decoder) (*yaml.Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if t == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		for decoder.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			child, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		// closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t}, nil
+	case json.Number:
+		tag := "!!float"
+		if _, err := t.Int64(); err == nil {
+			tag = "!!int"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(t)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
+
+// Filter returns a tree of the values matching the expression in document. The expression is
+// either JSONPath, e.g. $.items[*].name, or a jq path, e.g. .items[].name. A jq path is only a
+// path: pipes and functions of jq are not supported. Several matches are shown as an array.
+func Filter(document *yaml.Node, expression string) (*Node, error) {
+	expression = strings.TrimSpace(expression)
+	if strings.HasPrefix(expression, ".") && strings.Contains(expression, "|") {
+		return nil, errors.New("only jq paths are supported, not pipes")
+	}
+	path, err := yamlpath.NewPath(toJsonPath(expression))
+	if err != nil {
+		return nil, err
+	}
+	matches, err := path.Find(document)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree *Node
+	if len(matches) == 1 {
+		tree = newNode("", expression, matches[0], nil)
+	} else {
+		tree = newNode("", expression, &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: matches}, nil)
+	}
+	tree.Expanded = true
+	return tree, nil
+}
+
+// toJsonPath converts a jq path to JSONPath. JSONPath expressions are returned as is.
+func toJsonPath(expression string) string {
+	if !strings.HasPrefix(expression, ".") {
+		return expression
+	}
+	expression = strings.ReplaceAll(expression, "[]", "[*]")
+	if expression == "." {
+		return ROOT_PATH
+	}
+	if strings.HasPrefix(expression, ".[") {
+		return ROOT_PATH + expression[1:]
+	}
+	return ROOT_PATH + expression
+}
+
+func newNode(key, path string, value *yaml.Node, parent *Node) *Node {
+	if value.Kind == yaml.AliasNode && value.Alias != nil {
+		value = value.Alias
+	}
+	n := &Node{Key: key, Path: path, Parent: parent, value: value}
+	if parent != nil {
+		n.Depth = parent.Depth + 1
+	}
+	switch value.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			childKey := value.Content[i].Value
+			n.Children = append(n.Children, newNode(childKey, childPath(path, childKey), value.Content[i+1], n))
+		}
+	case yaml.SequenceNode:
+		for i, child := range value.Content {
+			n.Children = append(n.Children, newNode(strconv.Itoa(i), fmt.Sprintf("%s[%d]", path, i), child, n))
+		}
+	}
+	return n
+}
+
+func childPath(path, key string) string {
+	if identifier.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "['" + strings.ReplaceAll(strings.ReplaceAll(key, `\`, `\\`), `'`, `\'`) + "']"
+}
+
+// IsContainer tells if the node is an object or an array
+func (n *Node) IsContainer() bool {
+	return n.value.Kind == yaml.MappingNode || n.value.Kind == yaml.SequenceNode
+}
+
+// IsArray tells if the node is an array
+func (n *Node) IsArray() bool {
+	return n.value.Kind == yaml.SequenceNode
+}
+
+// Summary describes the node in a single line: scalars as json and objects and arrays by their size
+func (n *Node) Summary() string {
+	switch n.value.Kind {
+	case yaml.MappingNode:
+		return fmt.Sprintf("{…} %s", plural(len(n.Children), "key"))
+	case yaml.SequenceNode:
+		return fmt.Sprintf("[…] %s", plural(len(n.Children), "item"))
+	}
+	var b bytes.Buffer
+	writeJson(&b, n.value)
+	return b.String()
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// Json returns the value of the node as indented json
+func (n *Node) Json() string {
+	var b, indented bytes.Buffer
+	writeJson(&b, n.value)
+	if err := json.Indent(&indented, b.Bytes(), "", "  "); err != nil {
+		return b.String()
+	}
+	return indented.String()
+}
+
+// Visible returns the node and its descendants that are not inside collapsed nodes
+func (n *Node) Visible() []*Node {
+	visible := []*Node{n}
+	if n.Expanded {
+		for _, child := range n.Children {
+			visible = append(visible, child.Visible()...)
+		}
+	}
+	return visible
+}
+
+// SetExpanded expands or collapses the node and all of its descendants
+func (n *Node) SetExpanded(expanded bool) {
+	n.Expanded = expanded
+	for _, child := range n.Children {
+		child.SetExpanded(expanded)
+	}
+}
+
+func writeJson(b *bytes.Buffer, value *yaml.Node) {
+	if value.Kind == yaml.AliasNode && value.Alias != nil {
+		value = value.Alias
+	}
+	switch value.Kind {
+	case yaml.MappingNode:
+		b.WriteByte('{')
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeString(b, value.Content[i].Value)
+			b.WriteByte(':')
+			writeJson(b, value.Content[i+1])
+		}
+		b.WriteByte('}')
+	case yaml.SequenceNode:
+		b.WriteByte('[')
+		for i, child := range value.Content {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeJson(b, child)
+		}
+		b.WriteByte(']')
+	case yaml.ScalarNode:
+		switch value.Tag {
+		case "!!str":
+			writeString(b, value.Value)
+		case "!!null":
+			b.WriteString("null")
+		default:
+			b.WriteString(value.Value)
+		}
+	default:
+		b.WriteString("null")
+	}
+}
+
+func writeString(b *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(b)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	b.Truncate(b.Len() - 1)
+}
@@ -0,0 +1,120 @@
+package jsontree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const document = `{"name": "Rex <3", "tags": ["a", "b"], "owner": {"id": 1, "first name": null}, "age": 2.5, "ok": true}`
+
+func TestParse(t *testing.T) {
+	tree, _, err := Parse([]byte(document))
+	assert.NoError(t, err)
+	assert.Equal(t, ROOT_PATH, tree.Path)
+	assert.True(t, tree.Expanded)
+	assert.Equal(t, "{…} 5 keys", tree.Summary())
+
+	var keys, paths, summaries []string
+	for _, n := range tree.Children {
+		keys = append(keys, n.Key)
+		paths = append(paths, n.Path)
+		summaries = append(summaries, n.Summary())
+	}
+	assert.Equal(t, []string{"name", "tags", "owner", "age", "ok"}, keys)
+	assert.Equal(t, []string{"$.name", "$.tags", "$.owner", "$.age", "$.ok"}, paths)
+	assert.Equal(t, []string{`"Rex <3"`, "[…] 2 items", "{…} 2 keys", "2.5", "true"}, summaries)
+
+	single, _, err := Parse([]byte(`{"a": [1]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "{…} 1 key", single.Summary())
+	assert.Equal(t, "[…] 1 item", single.Children[0].Summary())
+
+	owner := tree.Children[2]
+	assert.Equal(t, "$.owner['first name']", owner.Children[1].Path)
+	assert.Equal(t, "$.tags[1]", tree.Children[1].Children[1].Path)
+	assert.Equal(t, "{\n  \"id\": 1,\n  \"first name\": null\n}", owner.Json())
+
+	// only the root is expanded
+	assert.Len(t, tree.Visible(), 6)
+	tree.SetExpanded(true)
+	assert.Len(t, tree.Visible(), 10)
+
+	_, _, err = Parse([]byte(`{"broken": `))
+	assert.Error(t, err)
+}
+
+func TestParseEscapesAndNumbers(t *testing.T) {
+	tree, root, err := Parse([]byte(`{"u": "https:\/\/x", "\u00e4": "\"q\"", "big": 12345678901234567890, "n": -1e3}`))
+	assert.NoError(t, err)
+	var keys, summaries []string
+	for _, n := range tree.Children {
+		keys = append(keys, n.Key)
+		summaries = append(summaries, n.Summary())
+	}
+	assert.Equal(t, []string{"u", "ä", "big", "n"}, keys)
+	assert.Equal(t, []string{`"https://x"`, `"\"q\""`, "12345678901234567890", "-1e3"}, summaries)
+
+	filtered, err := Filter(root, "$.u")
+	assert.NoError(t, err)
+	assert.Equal(t, `"https://x"`, filtered.Json())
+}
+
+func TestFilter(t *testing.T) {
+	_, root, err := Parse([]byte(document))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+		err        bool
+	}{
+		{
+			name:       "JSONPath",
+			expression: "$.owner.id",
+			expected:   "1",
+		},
+		{
+			name:       "jq path",
+			expression: ".owner",
+			expected:   "{\n  \"id\": 1,\n  \"first name\": null\n}",
+		},
+		{
+			name:       "jq iteration",
+			expression: ".tags[]",
+			expected:   "[\n  \"a\",\n  \"b\"\n]",
+		},
+		{
+			name:       "jq identity",
+			expression: ".",
+			expected:   "{\n  \"name\": \"Rex <3\",\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ],\n  \"owner\": {\n    \"id\": 1,\n    \"first name\": null\n  },\n  \"age\": 2.5,\n  \"ok\": true\n}",
+		},
+		{
+			name:       "No matches",
+			expression: "$.missing",
+			expected:   "[]",
+		},
+		{
+			name:       "Invalid expression",
+			expression: "$[",
+			err:        true,
+		},
+		{
+			name:       "jq pipe",
+			expression: ".tags | length",
+			err:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := Filter(root, tt.expression)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tree.Json())
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package loader
 
 import (
+	"errors"
 	"fmt"
 	"github.com/susiteemu/startpoint/core/model"
 	"io/fs"
@@ -13,10 +14,11 @@ import (
 )
 
 const (
-	YAML_EXT = ".yaml"
-	YML_EXT  = ".yml"
-	STAR_EXT = ".star"
-	LUA_EXT  = ".lua"
+	YAML_EXT    = ".yaml"
+	YML_EXT     = ".yml"
+	STAR_EXT    = ".star"
+	LUA_EXT     = ".lua"
+	GRAPHQL_EXT = ".graphql"
 )
 
 func ReadRequest(root, filename string) (*model.RequestMold, error) {
@@ -40,11 +42,40 @@ func ReadRequest(root, filename string) (*model.RequestMold, error) {
 		yamlRequest.Raw = strings.TrimSuffix(string(file), "\n")
 		// TODO: how to filter out yaml files that are not requests?
 		if yamlRequest.Url != "" || yamlRequest.Method != "" {
+			name := strings.TrimSuffix(filename, extension)
+			if strings.HasSuffix(filename, model.WEBSOCKET_YAML_EXT) {
+				name = strings.TrimSuffix(filename, model.WEBSOCKET_YAML_EXT)
+			}
 			request = &model.RequestMold{
 				Yaml:     yamlRequest,
 				Type:     model.CONTENT_TYPE_YAML,
 				Root:     root,
 				Filename: filename,
+				Name:     name,
+			}
+		}
+
+	case GRAPHQL_EXT:
+		file, err := os.ReadFile(path)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to read %s", path)
+			return nil, err
+		}
+		yamlRequest, err := model.ParseGraphQLDocument(strings.TrimSuffix(string(file), "\n"))
+		if errors.Is(err, model.ErrNoGraphQLHeader) {
+			// e.g. a schema next to the requests
+			return nil, fmt.Errorf("%s is not a request: %w", path, err)
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to parse file %s", path)
+			return nil, err
+		}
+		if yamlRequest.Url != "" {
+			request = &model.RequestMold{
+				Yaml:     yamlRequest,
+				Type:     model.CONTENT_TYPE_GRAPHQL,
+				Root:     root,
+				Filename: filename,
 				Name:     strings.TrimSuffix(filename, extension),
 			}
 		}
@@ -107,11 +138,13 @@ func ReadRequests(root string) ([]*model.RequestMold, error) {
 		filename := info.Name()
 
 		extension := filepath.Ext(filename)
-		if extension == YAML_EXT || extension == YML_EXT || extension == STAR_EXT || extension == LUA_EXT {
+		if extension == YAML_EXT || extension == YML_EXT || extension == STAR_EXT || extension == LUA_EXT || extension == GRAPHQL_EXT {
 			log.Debug().Msgf("Walk crossed a file %s", filename)
 
 			requestMold, err := ReadRequest(root, filename)
-			if err != nil {
+			if errors.Is(err, model.ErrNoGraphQLHeader) {
+				log.Debug().Msgf("Skipping %s without request attributes", filename)
+			} else if err != nil {
 				log.Error().Err(err).Msgf("Failed to read file %s", filename)
 			}
 			if requestMold != nil {
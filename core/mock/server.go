@@ -0,0 +1,299 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/susiteemu/startpoint/core/history"
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/templating/templateng"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	SOURCE_EXAMPLE = "example"
+	SOURCE_HISTORY = "history"
+)
+
+var templateVariablePattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+type Route struct {
+	RequestName string
+	Method      string
+	Path        string
+	pattern     *regexp.Regexp
+	templated   int
+	examples    []model.Example
+	history     *history.Entry
+}
+
+type Server struct {
+	routes   []*Route
+	servedCb func(r *http.Request, status int, route *Route, source string)
+}
+
+// NewRoutes resolves a route for each request in the workspace that has either
+// saved examples or a recorded history entry. Profile variables are filled
+// before the path template is extracted from the request url.
+func NewRoutes(requests []*model.RequestMold, profile *model.Profile) []*Route {
+	variables := map[string]string{}
+	if profile != nil && profile.Variables != nil {
+		variables = profile.Variables
+	}
+
+	routes := []*Route{}
+	for _, r := range requests {
+		if r.IsWebSocket() {
+			log.Debug().Msgf("Request %s is a websocket request: skipping it", r.Name)
+			continue
+		}
+		if r.IsGrpc() {
+			log.Debug().Msgf("Request %s is a grpc request: skipping it", r.Name)
+			continue
+		}
+		if r.IsGraphQL() {
+			log.Debug().Msgf("Request %s is a graphql request: skipping it", r.Name)
+			continue
+		}
+		var examples []model.Example
+		if r.Yaml != nil {
+			for _, e := range r.Yaml.Examples {
+				examples = append(examples, processExample(e, variables))
+			}
+		}
+		entry, err := history.Latest(r.Root, r.Name)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to read history of %s", r.Name)
+		}
+		if len(examples) == 0 && entry == nil {
+			log.Debug().Msgf("Request %s has no examples nor history: skipping it", r.Name)
+			continue
+		}
+
+		method := strings.ToUpper(strings.TrimSpace(r.Method()))
+		if method == "" {
+			method = http.MethodGet
+		}
+		path := PathTemplate(processTemplates(r.Url(), variables))
+		routes = append(routes, &Route{
+			RequestName: r.Name,
+			Method:      method,
+			Path:        path,
+			pattern:     compilePathPattern(path),
+			templated:   len(templateVariablePattern.FindAllString(path, -1)),
+			examples:    examples,
+			history:     entry,
+		})
+	}
+
+	// prefer routes with fewer template variables, e.g. /pets/mine over /pets/{id}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].templated != routes[j].templated {
+			return routes[i].templated < routes[j].templated
+		}
+		return len(routes[i].Path) > len(routes[j].Path)
+	})
+
+	return routes
+}
+
+func NewServer(routes []*Route, servedCb func(r *http.Request, status int, route *Route, source string)) *Server {
+	return &Server{
+		routes:   routes,
+		servedCb: servedCb,
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, route := range s.routes {
+		if route.Method != r.Method || !route.pattern.MatchString(r.URL.Path) {
+			continue
+		}
+		if example, ok := route.matchExample(r.Header); ok {
+			status := writeExample(w, example)
+			s.served(r, status, route, SOURCE_EXAMPLE)
+			return
+		}
+		if route.history != nil {
+			status := writeHistoryEntry(w, route.history)
+			s.served(r, status, route, SOURCE_HISTORY)
+			return
+		}
+	}
+
+	http.Error(w, fmt.Sprintf("No mocked response for %s %s", r.Method, r.URL.Path), http.StatusNotFound)
+	s.served(r, http.StatusNotFound, nil, "")
+}
+
+func (s *Server) served(r *http.Request, status int, route *Route, source string) {
+	if s.servedCb != nil {
+		s.servedCb(r, status, route, source)
+	}
+}
+
+// matchExample returns the first example whose match headers are all present in the request.
+// Examples with match headers are tried before the ones without.
+func (route *Route) matchExample(header http.Header) (model.Example, bool) {
+	var fallback *model.Example
+	for i, e := range route.examples {
+		if len(e.Match) == 0 {
+			if fallback == nil {
+				fallback = &route.examples[i]
+			}
+			continue
+		}
+		if headersMatch(e.Match, header) {
+			return e, true
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return model.Example{}, false
+}
+
+func headersMatch(expected model.Headers, actual http.Header) bool {
+	for k, v := range expected {
+		values := actual.Values(k)
+		if len(values) == 0 {
+			return false
+		}
+		if strings.Join(values, ",") != v.ToString() {
+			return false
+		}
+	}
+	return true
+}
+
+func writeExample(w http.ResponseWriter, e model.Example) int {
+	status := e.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	for k, v := range e.Headers {
+		for _, hv := range v {
+			w.Header().Add(k, strings.TrimSpace(hv))
+		}
+	}
+
+	var body []byte
+	switch b := e.Body.(type) {
+	case nil:
+	case string:
+		body = []byte(b)
+	default:
+		marshalled, err := json.Marshal(b)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to marshal example body %v", b)
+			http.Error(w, "Failed to marshal example body", http.StatusInternalServerError)
+			return http.StatusInternalServerError
+		}
+		body = marshalled
+		if w.Header().Get(model.HEADER_NAME_CONTENT_TYPE) == "" {
+			w.Header().Set(model.HEADER_NAME_CONTENT_TYPE, model.CONTENT_TYPE_APPLICATION_JSON)
+		}
+	}
+
+	w.WriteHeader(status)
+	w.Write(body)
+	return status
+}
+
+func writeHistoryEntry(w http.ResponseWriter, entry *history.Entry) int {
+	for k, v := range entry.Headers {
+		// these are recomputed for the mocked response
+		if http.CanonicalHeaderKey(k) == "Content-Length" || http.CanonicalHeaderKey(k) == "Transfer-Encoding" {
+			continue
+		}
+		for _, hv := range v {
+			w.Header().Add(k, hv)
+		}
+	}
+	status := entry.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write([]byte(entry.Body))
+	return status
+}
+
+// PathTemplate strips scheme, host and query from the request url, e.g.
+// "{domain}/pets/{id}?q=1" and "http://localhost/pets/{id}" both become "/pets/{id}".
+func PathTemplate(url string) string {
+	path := url
+	if idx := strings.Index(path, "?"); idx >= 0 {
+		path = path[:idx]
+	}
+	if idx := strings.Index(path, "://"); idx >= 0 {
+		path = path[idx+len("://"):]
+		if slash := strings.Index(path, "/"); slash >= 0 {
+			path = path[slash:]
+		} else {
+			path = "/"
+		}
+	} else if strings.HasPrefix(path, "{") {
+		// unfilled template variable in place of the base url
+		if end := strings.Index(path, "}"); end >= 0 {
+			path = path[end+1:]
+		}
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+func compilePathPattern(path string) *regexp.Regexp {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	cursor := 0
+	for _, match := range templateVariablePattern.FindAllStringIndex(path, -1) {
+		pattern.WriteString(regexp.QuoteMeta(path[cursor:match[0]]))
+		pattern.WriteString("[^/]+")
+		cursor = match[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(strings.TrimSuffix(path[cursor:], "/")))
+	pattern.WriteString("/?$")
+	return regexp.MustCompile(pattern.String())
+}
+
+func processExample(e model.Example, variables map[string]string) model.Example {
+	processed := model.Example{
+		Name:    e.Name,
+		Status:  e.Status,
+		Match:   processHeaders(e.Match, variables),
+		Headers: processHeaders(e.Headers, variables),
+		Body:    e.Body,
+	}
+	if asStr, ok := e.Body.(string); ok {
+		processed.Body = processTemplates(asStr, variables)
+	}
+	return processed
+}
+
+func processHeaders(headers model.Headers, variables map[string]string) model.Headers {
+	if headers == nil {
+		return nil
+	}
+	processed := model.Headers{}
+	for k, v := range headers {
+		processed[k] = model.HeaderValues{}
+		for _, hv := range v {
+			processed[k] = append(processed[k], processTemplates(hv, variables))
+		}
+	}
+	return processed
+}
+
+func processTemplates(s string, variables map[string]string) string {
+	for k, v := range variables {
+		s, _ = templateng.ProcessTemplateVariable(s, k, v)
+	}
+	return s
+}
@@ -0,0 +1,174 @@
+package mock
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/history"
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathTemplate(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "http://localhost:8000/pets/{id}", expected: "/pets/{id}"},
+		{url: "{domain}/pets/{id}?limit=10", expected: "/pets/{id}"},
+		{url: "https://foobar.com", expected: "/"},
+		{url: "pets", expected: "/pets"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PathTemplate(tt.url))
+		})
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	root := t.TempDir()
+
+	requests := []*model.RequestMold{
+		{
+			Name: "Get pet",
+			Root: root,
+			Type: model.CONTENT_TYPE_YAML,
+			Yaml: &model.YamlRequest{
+				Url:    "{domain}/pets/{id}",
+				Method: "GET",
+				Examples: []model.Example{
+					{
+						Match:  model.Headers{"Accept": {"application/xml"}},
+						Status: 200,
+						Headers: model.Headers{
+							"Content-Type": {"application/xml"},
+						},
+						Body: "<pet><name>Fluffy</name></pet>",
+					},
+					{
+						Status: 200,
+						Body:   map[string]interface{}{"name": "Fluffy"},
+					},
+				},
+			},
+		},
+		{
+			Name: "Get my pet",
+			Root: root,
+			Type: model.CONTENT_TYPE_YAML,
+			Yaml: &model.YamlRequest{
+				Url:    "{domain}/pets/mine",
+				Method: "GET",
+				Examples: []model.Example{
+					{Status: 404},
+				},
+			},
+		},
+		{
+			Name: "Delete pet",
+			Root: root,
+			Type: model.CONTENT_TYPE_YAML,
+			Yaml: &model.YamlRequest{
+				Url:    "{domain}/pets/{id}",
+				Method: "DELETE",
+			},
+		},
+		{
+			Name: "No examples",
+			Root: root,
+			Type: model.CONTENT_TYPE_YAML,
+			Yaml: &model.YamlRequest{
+				Url:    "{domain}/owners",
+				Method: "GET",
+			},
+		},
+	}
+
+	err := history.Save(root, &model.Response{
+		RequestName: "Delete pet",
+		StatusCode:  204,
+		Headers:     model.Headers{"X-Deleted": {"true"}},
+		ReceivedAt:  time.Now(),
+	})
+	assert.Nil(t, err)
+
+	profile := &model.Profile{Variables: map[string]string{"domain": "http://localhost:8000"}}
+	routes := NewRoutes(requests, profile)
+	assert.Equal(t, 3, len(routes))
+
+	server := httptest.NewServer(NewServer(routes, nil))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		headers        map[string]string
+		expectedStatus int
+		expectedBody   string
+		expectedHeader map[string]string
+	}{
+		{
+			name:           "Example matching headers",
+			method:         "GET",
+			path:           "/pets/1",
+			headers:        map[string]string{"Accept": "application/xml"},
+			expectedStatus: 200,
+			expectedBody:   "<pet><name>Fluffy</name></pet>",
+			expectedHeader: map[string]string{"Content-Type": "application/xml"},
+		},
+		{
+			name:           "Example without match headers",
+			method:         "GET",
+			path:           "/pets/1",
+			expectedStatus: 200,
+			expectedBody:   `{"name":"Fluffy"}`,
+			expectedHeader: map[string]string{"Content-Type": "application/json"},
+		},
+		{
+			name:           "Less templated path wins",
+			method:         "GET",
+			path:           "/pets/mine",
+			expectedStatus: 404,
+		},
+		{
+			name:           "History entry",
+			method:         "DELETE",
+			path:           "/pets/1",
+			expectedStatus: 204,
+			expectedHeader: map[string]string{"X-Deleted": "true"},
+		},
+		{
+			name:           "No route",
+			method:         "GET",
+			path:           "/owners",
+			expectedStatus: 404,
+			expectedBody:   "No mocked response for GET /owners\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+			assert.Nil(t, err)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			assert.Nil(t, err)
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if len(tt.expectedBody) > 0 {
+				assert.Equal(t, tt.expectedBody, string(body))
+			}
+			for k, v := range tt.expectedHeader {
+				assert.Equal(t, v, resp.Header.Get(k))
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package model
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -14,18 +16,30 @@ const HEADER_NAME_AUTHORIZATION = "Authorization"
 const HEADER_VALUE_BASIC_AUTH = "Basic"
 const HEADER_VALUE_BEARER_AUTH = "Bearer"
 const HEADER_NAME_CONTENT_TYPE = "Content-Type"
+const HEADER_NAME_COOKIE = "Cookie"
+const API_KEY_IN_HEADER = "header"
+const API_KEY_IN_QUERY = "query"
+const API_KEY_IN_COOKIE = "cookie"
 const CONTENT_TYPE_PLAINTEXT = "text/plain"
 const CONTENT_TYPE_APPLICATION_JSON = "application/json"
 const CONTENT_TYPE_APPLICATION_XML = "application/xml"
 const CONTENT_TYPE_TEXT_HTML = "text/html"
 const CONTENT_TYPE_FORM_URLENCODED = "application/x-www-form-urlencoded"
 const CONTENT_TYPE_MULTIPART_FORM = "multipart/form-data"
+const CONTENT_TYPE_APPLICATION_YAML = "application/yaml"
+const CONTENT_TYPE_TEXT_CSV = "text/csv"
+const CONTENT_TYPE_NDJSON = "application/x-ndjson"
+const CONTENT_TYPE_MSGPACK = "application/msgpack"
 
 type Body interface{}
 type FormData interface{}
 type HeaderValues []string
 type Headers map[string]HeaderValues
 
+// QueryValues holds values of a query parameter. In yaml it is either a single value or a list.
+type QueryValues []string
+type QueryParams map[string]QueryValues
+
 /*func (body *Body) UnmarshalYAML(node *yaml.Node) error {
 	value := node.Value
 	ba := []byte(value)
@@ -43,9 +57,85 @@ func (headerValues HeaderValues) MarshalYAML() (interface{}, error) {
 	return strings.Join(headerValues, ","), nil
 }
 
+func (queryValues *QueryValues) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*queryValues = QueryValues{node.Value}
+	case yaml.SequenceNode:
+		values := QueryValues{}
+		for _, child := range node.Content {
+			if child.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: query parameter value must be a scalar", child.Line)
+			}
+			values = append(values, child.Value)
+		}
+		*queryValues = values
+	default:
+		return fmt.Errorf("line %d: query parameter must be a value or a list of values", node.Line)
+	}
+	return nil
+}
+
 func (headerValues *HeaderValues) ToString() string {
 	return strings.Join(*headerValues, ",")
 }
+
+// Clone returns a copy of headers which can be modified without modifying the original
+func (headers Headers) Clone() Headers {
+	if headers == nil {
+		return nil
+	}
+	clone := make(Headers, len(headers))
+	for k, v := range headers {
+		clone[k] = slices.Clone(v)
+	}
+	return clone
+}
+
+// Clone returns a copy of query params which can be modified without modifying the original
+func (query QueryParams) Clone() QueryParams {
+	if query == nil {
+		return nil
+	}
+	clone := make(QueryParams, len(query))
+	for k, v := range query {
+		clone[k] = slices.Clone(v)
+	}
+	return clone
+}
+
+// cloneValue returns a deep copy of maps and lists of a value read from yaml
+func cloneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return cloneMap(v)
+	case map[interface{}]interface{}:
+		clone := make(map[interface{}]interface{}, len(v))
+		for k, item := range v {
+			clone[k] = cloneValue(item)
+		}
+		return clone
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, item := range v {
+			clone[i] = cloneValue(item)
+		}
+		return clone
+	}
+	return value
+}
+
+func cloneMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		clone[k] = cloneValue(v)
+	}
+	return clone
+}
+
 func (headers *Headers) FromMap(m map[string][]string) Headers {
 	responseHeaders := make(map[string]HeaderValues)
 	for k, v := range m {
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+const CONTENT_TYPE_GRAPHQL = "graphql"
+
+// GRAPHQL_HEADER_MARKER starts and ends the request attributes in the leading comments of a
+// .graphql request
+const GRAPHQL_HEADER_MARKER = "# ---"
+
+// ErrNoGraphQLHeader tells that a .graphql document is not a request, e.g. it is a schema
+var ErrNoGraphQLHeader = errors.New("graphql document has no request attributes")
+
+// GraphQLRequest is sent as a json body {"query", "variables", "operationName"} or, with GET
+// method, as query parameters of the same names
+type GraphQLRequest struct {
+	Query         string                 `yaml:"query,omitempty"`
+	Variables     map[string]interface{} `yaml:"variables,omitempty"`
+	OperationName string                 `yaml:"operation_name,omitempty"`
+}
+
+// graphQLHeader holds attributes of a .graphql request written in its leading comments
+type graphQLHeader struct {
+	PrevReq string          `yaml:"prev_req,omitempty"`
+	Url     string          `yaml:"url"`
+	Method  string          `yaml:"method,omitempty"`
+	Headers Headers         `yaml:"headers,omitempty"`
+	Output  string          `yaml:"output,omitempty"`
+	GraphQL *GraphQLRequest `yaml:"graphql,omitempty"`
+}
+
+// IsGraphQL tells if the mold is a graphql request
+func (r *RequestMold) IsGraphQL() bool {
+	return r.Yaml != nil && r.Yaml.GraphQL != nil
+}
+
+// ParseGraphQLDocument reads a .graphql request. Its request attributes are yaml in comment
+// lines between two GRAPHQL_HEADER_MARKER lines at the start of the document, e.g.
+//
+//	# ---
+//	# url: "{domain}/graphql"
+//	# graphql:
+//	#   variables:
+//	#     id: 1
+//	# ---
+//	query Pet($id: ID!) { pet(id: $id) { name } }
+//
+// and the rest of the document is the query. Documents without the attributes return
+// ErrNoGraphQLHeader.
+func ParseGraphQLDocument(document string) (*YamlRequest, error) {
+	lines := strings.Split(strings.TrimLeft(document, "\n"), "\n")
+	if strings.TrimSpace(lines[0]) != GRAPHQL_HEADER_MARKER {
+		return nil, ErrNoGraphQLHeader
+	}
+	end := -1
+	var header []string
+	for i, line := range lines[1:] {
+		if strings.TrimSpace(line) == GRAPHQL_HEADER_MARKER {
+			end = i + 1
+			break
+		}
+		if !strings.HasPrefix(line, "#") {
+			return nil, fmt.Errorf("graphql request attributes must be comments, got %q", line)
+		}
+		line = strings.TrimPrefix(line, "#")
+		header = append(header, strings.TrimPrefix(line, " "))
+	}
+	if end < 0 {
+		return nil, fmt.Errorf("graphql request attributes must end with %q", GRAPHQL_HEADER_MARKER)
+	}
+
+	yamlRequest := &YamlRequest{}
+	err := yaml.Unmarshal([]byte(strings.Join(header, "\n")), yamlRequest)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to unmarshal attributes of graphql request")
+		return nil, err
+	}
+	if yamlRequest.GraphQL == nil {
+		yamlRequest.GraphQL = &GraphQLRequest{}
+	}
+	yamlRequest.GraphQL.Query = strings.TrimSpace(strings.Join(lines[end+1:], "\n"))
+	yamlRequest.Raw = document
+	return yamlRequest, nil
+}
+
+// FormatGraphQLDocument writes yaml request as a .graphql document with attributes in leading
+// comments between GRAPHQL_HEADER_MARKER lines and the query after them
+func FormatGraphQLDocument(yamlRequest *YamlRequest) (string, error) {
+	header := graphQLHeader{
+		PrevReq: yamlRequest.PrevReq,
+		Url:     yamlRequest.Url,
+		Method:  yamlRequest.Method,
+		Headers: yamlRequest.Headers,
+		Output:  yamlRequest.Output,
+	}
+	query := ""
+	if yamlRequest.GraphQL != nil {
+		query = yamlRequest.GraphQL.Query
+		if len(yamlRequest.GraphQL.Variables) > 0 || yamlRequest.GraphQL.OperationName != "" {
+			header.GraphQL = &GraphQLRequest{
+				Variables:     yamlRequest.GraphQL.Variables,
+				OperationName: yamlRequest.GraphQL.OperationName,
+			}
+		}
+	}
+	var asYaml strings.Builder
+	encoder := yaml.NewEncoder(&asYaml)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(header); err != nil {
+		return "", err
+	}
+	var document strings.Builder
+	document.WriteString(GRAPHQL_HEADER_MARKER + "\n")
+	for _, line := range strings.Split(strings.TrimSuffix(asYaml.String(), "\n"), "\n") {
+		document.WriteString("# " + line + "\n")
+	}
+	document.WriteString(GRAPHQL_HEADER_MARKER + "\n")
+	document.WriteString(query)
+	return document.String(), nil
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGraphQLDocument(t *testing.T) {
+	document := `# ---
+# url: "{domain}/graphql"
+# graphql:
+#   variables:
+#     id: 1
+# ---
+# pet by its id
+query Pet($id: ID!) { pet(id: $id) { name } }`
+
+	yamlRequest, err := ParseGraphQLDocument(document)
+	assert.Nil(t, err)
+	assert.Equal(t, "{domain}/graphql", yamlRequest.Url)
+	assert.Equal(t, map[string]interface{}{"id": 1}, yamlRequest.GraphQL.Variables)
+	assert.Equal(t, "# pet by its id\nquery Pet($id: ID!) { pet(id: $id) { name } }", yamlRequest.GraphQL.Query)
+	assert.Equal(t, document, yamlRequest.Raw)
+
+	formatted, err := FormatGraphQLDocument(yamlRequest)
+	assert.Nil(t, err)
+	parsed, err := ParseGraphQLDocument(formatted)
+	assert.Nil(t, err)
+	assert.Equal(t, yamlRequest.Url, parsed.Url)
+	assert.Equal(t, yamlRequest.GraphQL.Variables, parsed.GraphQL.Variables)
+	assert.Equal(t, yamlRequest.GraphQL.Query, parsed.GraphQL.Query)
+}
+
+func TestParseGraphQLDocumentWithoutAttributes(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		err      error
+	}{
+		{"schema", "# pets\ntype Pet { name: String }", ErrNoGraphQLHeader},
+		{"query", "{ pets { name } }", ErrNoGraphQLHeader},
+		{"unclosed", "# ---\n# url: http://localhost/graphql\n{ pets { name } }", nil},
+		{"not a comment", "# ---\nurl: http://localhost/graphql\n# ---\n{ pets { name } }", nil},
+	}
+	for _, tt := range tests {
+		yamlRequest, err := ParseGraphQLDocument(tt.document)
+		assert.Nil(t, yamlRequest, tt.name)
+		assert.Error(t, err, tt.name)
+		if tt.err != nil {
+			assert.ErrorIs(t, err, tt.err, tt.name)
+		}
+	}
+}
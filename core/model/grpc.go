@@ -0,0 +1,20 @@
+package model
+
+const PROTOCOL_GRPC = "grpc"
+
+// GrpcRequest names the method to call. Its schema is read from ProtoFiles when given and
+// otherwise resolved using server reflection.
+type GrpcRequest struct {
+	Service     string   `yaml:"service"`
+	Method      string   `yaml:"method"`
+	ProtoFiles  []string `yaml:"proto_files,omitempty"`
+	ImportPaths []string `yaml:"import_paths,omitempty"`
+}
+
+// IsGrpc tells if the mold is a grpc request either by its protocol or by having grpc section
+func (r *RequestMold) IsGrpc() bool {
+	if r.Yaml == nil {
+		return false
+	}
+	return r.Yaml.Protocol == PROTOCOL_GRPC || r.Yaml.Grpc != nil
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MultipartPart is a part of a multipart/form-data body. It holds either a file or inline content.
+type MultipartPart struct {
+	Name        string
+	File        string
+	Content     string
+	ContentType string
+	Filename    string
+}
+
+// IsFile tells if the part is sent as a file, i.e. it has a filename in its Content-Disposition
+func (p MultipartPart) IsFile() bool {
+	return len(p.File) > 0 || len(p.Filename) > 0
+}
+
+// MultipartParts reads the parts of a multipart/form-data body. Value of a field is either text,
+// "@path" of a file, a part with file or content and optional content_type and filename, or a
+// list of those for several parts with the same name. Fields are returned in the order of their
+// names.
+func (r *Request) MultipartParts() ([]MultipartPart, error) {
+	fields := map[string]interface{}{}
+	switch body := r.Body.(type) {
+	case nil:
+	case map[string]interface{}:
+		fields = body
+	case map[string]string:
+		for k, v := range body {
+			fields[k] = v
+		}
+	case map[string][]string:
+		for k, v := range body {
+			values := []interface{}{}
+			for _, value := range v {
+				values = append(values, value)
+			}
+			fields[k] = values
+		}
+	default:
+		return nil, fmt.Errorf("multipart body must be a map, got %T", r.Body)
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var parts []MultipartPart
+	for _, name := range names {
+		values, isList := fields[name].([]interface{})
+		if !isList {
+			values = []interface{}{fields[name]}
+		}
+		for _, value := range values {
+			part, err := toMultipartPart(name, value)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+		}
+	}
+	return parts, nil
+}
+
+func toMultipartPart(name string, value interface{}) (MultipartPart, error) {
+	part := MultipartPart{Name: name}
+	var spec map[string]interface{}
+	switch v := value.(type) {
+	case string:
+		if path, isFile := strings.CutPrefix(v, "@"); isFile {
+			part.File = path
+		} else {
+			part.Content = v
+		}
+		return part, nil
+	case map[string]interface{}:
+		spec = v
+	case map[interface{}]interface{}:
+		spec = map[string]interface{}{}
+		for k, specValue := range v {
+			spec[fmt.Sprint(k)] = specValue
+		}
+	case []interface{}:
+		return part, fmt.Errorf("multipart field %s must not have nested lists", name)
+	default:
+		part.Content = fmt.Sprint(v)
+		return part, nil
+	}
+
+	file, hasFile := spec["file"]
+	content, hasContent := spec["content"]
+	if hasFile == hasContent {
+		return part, fmt.Errorf("multipart part %s must have either file or content", name)
+	}
+	for key, specValue := range spec {
+		switch key {
+		case "file", "content", "content_type", "filename":
+		default:
+			return part, fmt.Errorf("multipart part %s has unknown property %s", name, key)
+		}
+		switch specValue.(type) {
+		case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+			return part, fmt.Errorf("multipart part %s must have a single value in %s", name, key)
+		}
+	}
+	if hasFile {
+		part.File = fmt.Sprint(file)
+	} else {
+		part.Content = fmt.Sprint(content)
+	}
+	if contentType, ok := spec["content_type"]; ok {
+		part.ContentType = fmt.Sprint(contentType)
+	}
+	if filename, ok := spec["filename"]; ok {
+		part.Filename = fmt.Sprint(filename)
+	}
+	return part, nil
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultipartParts(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     Body
+		expected []MultipartPart
+		err      bool
+	}{
+		{
+			name: "Text fields and files",
+			body: map[string]interface{}{
+				"title": "Image",
+				"id":    1,
+				"file":  "@resources/a.png",
+			},
+			expected: []MultipartPart{
+				{Name: "file", File: "resources/a.png"},
+				{Name: "id", Content: "1"},
+				{Name: "title", Content: "Image"},
+			},
+		},
+		{
+			name: "Parts with content type and filename",
+			body: map[string]interface{}{
+				"metadata": map[string]interface{}{"content": `{"a": 1}`, "content_type": "application/json"},
+				"images": []interface{}{
+					map[interface{}]interface{}{"file": "a.png", "content_type": "image/png"},
+					map[string]interface{}{"file": "b.png", "filename": "renamed.png"},
+					"@c.png",
+				},
+			},
+			expected: []MultipartPart{
+				{Name: "images", File: "a.png", ContentType: "image/png"},
+				{Name: "images", File: "b.png", Filename: "renamed.png"},
+				{Name: "images", File: "c.png"},
+				{Name: "metadata", Content: `{"a": 1}`, ContentType: "application/json"},
+			},
+		},
+		{
+			name: "Fields of a script",
+			body: map[string][]string{"tags": {"a", "b"}},
+			expected: []MultipartPart{
+				{Name: "tags", Content: "a"},
+				{Name: "tags", Content: "b"},
+			},
+		},
+		{
+			name: "Part without file or content",
+			body: map[string]interface{}{"metadata": map[string]interface{}{"content_type": "application/json"}},
+			err:  true,
+		},
+		{
+			name: "Part with both file and content",
+			body: map[string]interface{}{"metadata": map[string]interface{}{"file": "a.json", "content": "{}"}},
+			err:  true,
+		},
+		{
+			name: "Part with unknown property",
+			body: map[string]interface{}{"metadata": map[string]interface{}{"file": "a.json", "type": "json"}},
+			err:  true,
+		},
+		{
+			name: "Body that is not a map",
+			body: "title=Image",
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := Request{Body: tt.body}
+			parts, err := request.MultipartParts()
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, parts)
+		})
+	}
+}
@@ -2,6 +2,8 @@ package model
 
 import (
 	"fmt"
+	"maps"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -36,18 +38,76 @@ type BasicAuth struct {
 	Password string `yaml:"password"`
 }
 
+type DigestAuth struct {
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+}
+
+type AwsSigV4Auth struct {
+	AccessKey    string `yaml:"access_key"`
+	SecretKey    string `yaml:"secret_key"`
+	SessionToken string `yaml:"session_token,omitempty"`
+	Region       string `yaml:"region"`
+	Service      string `yaml:"service"`
+}
+
+type ApiKeyAuth struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+	In    string `yaml:"in,omitempty"`
+}
+
+type OAuth2Auth struct {
+	GrantType    string   `yaml:"grant_type"`
+	TokenUrl     string   `yaml:"token_url"`
+	ClientId     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret,omitempty"`
+	ClientAuth   string   `yaml:"client_auth,omitempty"`
+	Scopes       []string `yaml:"scopes,omitempty"`
+	Username     string   `yaml:"username,omitempty"`
+	Password     string   `yaml:"password,omitempty"`
+	RefreshToken string   `yaml:"refresh_token,omitempty"`
+}
+
 type Auth struct {
-	Basic  BasicAuth `yaml:"basic_auth"`
-	Bearer string    `yaml:"bearer_token"`
+	Basic    BasicAuth    `yaml:"basic_auth"`
+	Bearer   string       `yaml:"bearer_token"`
+	Digest   DigestAuth   `yaml:"digest_auth,omitempty"`
+	AwsSigV4 AwsSigV4Auth `yaml:"aws_sigv4,omitempty"`
+	ApiKey   ApiKeyAuth   `yaml:"api_key,omitempty"`
+	OAuth2   OAuth2Auth   `yaml:"oauth2,omitempty"`
+}
+
+type Example struct {
+	Name    string  `yaml:"name,omitempty"`
+	Match   Headers `yaml:"match,omitempty"`
+	Status  int     `yaml:"status,omitempty"`
+	Headers Headers `yaml:"headers,omitempty"`
+	Body    Body    `yaml:"body,omitempty"`
 }
 
 type Request struct {
-	Headers Headers
-	Options map[string]interface{}
-	Body    Body
-	Url     string
-	Method  string
-	Output  string
+	Headers   Headers
+	Options   map[string]interface{}
+	Body      Body
+	Url       string
+	Method    string
+	Output    string
+	CookieJar http.CookieJar
+	OAuth2    *OAuth2Auth
+	Digest    *DigestAuth
+	AwsSigV4  *AwsSigV4Auth
+	// OnEvent receives server-sent events as they arrive when set
+	OnEvent  func(Event)
+	Protocol string
+	Messages []WebSocketMessage
+	Until    string
+	Grpc     *GrpcRequest
+	// Query and PathParams are already merged into Url, they are kept for printing
+	Query      QueryParams
+	PathParams map[string]string
+	// Root is the workspace of the request where e.g. oauth2 tokens are stored
+	Root string
 }
 
 type RequestMold struct {
@@ -60,15 +120,25 @@ type RequestMold struct {
 }
 
 type YamlRequest struct {
-	PrevReq string                 `yaml:"prev_req,omitempty"`
-	Url     string                 `yaml:"url"`
-	Method  string                 `yaml:"method"`
-	Headers Headers                `yaml:"headers,omitempty"`
-	Body    Body                   `yaml:"body,omitempty"`
-	Output  string                 `yaml:"output,omitempty"`
-	Options map[string]interface{} `yaml:"options,omitempty"`
-	Raw     string                 `yaml:"raw,omitempty"`
-	Auth    Auth                   `yaml:"auth,omitempty"`
+	PrevReq          string                 `yaml:"prev_req,omitempty"`
+	Url              string                 `yaml:"url"`
+	Method           string                 `yaml:"method"`
+	Headers          Headers                `yaml:"headers,omitempty"`
+	Query            QueryParams            `yaml:"query,omitempty"`
+	PathParams       map[string]string      `yaml:"path_params,omitempty"`
+	Body             Body                   `yaml:"body,omitempty"`
+	BodyFile         string                 `yaml:"body_file,omitempty"`
+	BodyFileTemplate bool                   `yaml:"body_file_template,omitempty"`
+	Output           string                 `yaml:"output,omitempty"`
+	Options          map[string]interface{} `yaml:"options,omitempty"`
+	Raw              string                 `yaml:"raw,omitempty"`
+	Auth             Auth                   `yaml:"auth,omitempty"`
+	Examples         []Example              `yaml:"examples,omitempty"`
+	Protocol         string                 `yaml:"protocol,omitempty"`
+	Messages         []WebSocketMessage     `yaml:"messages,omitempty"`
+	Until            string                 `yaml:"until,omitempty"`
+	Grpc             *GrpcRequest           `yaml:"grpc,omitempty"`
+	GraphQL          *GraphQLRequest        `yaml:"graphql,omitempty"`
 }
 
 type ScriptableRequest struct {
@@ -181,7 +251,14 @@ func (r *RequestMold) Method() string {
 
 func (r *RequestMold) Raw() string {
 	if r.Yaml != nil {
-		if r.Yaml.Raw == "" {
+		if r.Yaml.Raw == "" && r.Type == CONTENT_TYPE_GRAPHQL {
+			document, err := FormatGraphQLDocument(r.Yaml)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to format GraphQL request")
+			} else {
+				r.Yaml.Raw = document
+			}
+		} else if r.Yaml.Raw == "" {
 			asYaml, err := yaml.Marshal(r.Yaml)
 			if err != nil {
 				log.Error().Err(err).Msg("Failed to marshal YAML request to YAML")
@@ -214,8 +291,13 @@ func (r *RequestMold) PreviousReq() string {
 func (r *RequestMold) ChangePreviousReq(prevReq string) {
 	if r.Yaml != nil {
 		r.Yaml.PrevReq = prevReq
-		pattern := regexp.MustCompile(`(?mU)^prev_req:(.*)$`)
-		changed := pattern.ReplaceAllString(r.Yaml.Raw, fmt.Sprintf("prev_req: \"%s\"", prevReq))
+		prefix := ""
+		if r.Type == CONTENT_TYPE_GRAPHQL {
+			// attributes of graphql requests are in comments
+			prefix = "# "
+		}
+		pattern := regexp.MustCompile(`(?mU)^` + prefix + `prev_req:(.*)$`)
+		changed := pattern.ReplaceAllString(r.Yaml.Raw, fmt.Sprintf("%sprev_req: \"%s\"", prefix, prevReq))
 		r.Yaml.Raw = changed
 	} else if r.Scriptable != nil {
 		switch r.Type {
@@ -248,6 +330,8 @@ func (r *RequestMold) DeleteFromFS() bool {
 	return true
 }
 
+// Clone returns a copy of the request mold. Maps of the request are copied too so that the copy
+// can be built and run concurrently with the original.
 func (r *RequestMold) Clone() RequestMold {
 	copy := RequestMold{
 		Type:     r.Type,
@@ -258,14 +342,25 @@ func (r *RequestMold) Clone() RequestMold {
 
 	if r.Yaml != nil {
 		yamlRequest := YamlRequest{
-			PrevReq: r.Yaml.PrevReq,
-			Url:     r.Yaml.Url,
-			Method:  r.Yaml.Method,
-			Headers: r.Yaml.Headers,
-			Body:    r.Yaml.Body,
-			Output:  r.Yaml.Output,
-			Raw:     r.Yaml.Raw,
-			Auth:    r.Yaml.Auth,
+			PrevReq:          r.Yaml.PrevReq,
+			Url:              r.Yaml.Url,
+			Method:           r.Yaml.Method,
+			Headers:          r.Yaml.Headers.Clone(),
+			Query:            r.Yaml.Query.Clone(),
+			PathParams:       maps.Clone(r.Yaml.PathParams),
+			Body:             cloneValue(r.Yaml.Body),
+			BodyFile:         r.Yaml.BodyFile,
+			BodyFileTemplate: r.Yaml.BodyFileTemplate,
+			Output:           r.Yaml.Output,
+			Options:          cloneMap(r.Yaml.Options),
+			Raw:              r.Yaml.Raw,
+			Auth:             r.Yaml.Auth,
+			Examples:         r.Yaml.Examples,
+			Protocol:         r.Yaml.Protocol,
+			Messages:         r.Yaml.Messages,
+			Until:            r.Yaml.Until,
+			Grpc:             r.Yaml.Grpc,
+			GraphQL:          r.Yaml.GraphQL,
 		}
 		copy.Yaml = &yamlRequest
 	} else if r.Scriptable != nil {
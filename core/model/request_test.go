@@ -237,3 +237,30 @@ method: POST
 	wantedPrevReq = "Some other previous request"
 	assert.Equal(t, wantedPrevReq, yamlRequest.PreviousReq())
 }
+
+func TestCloneCopiesMaps(t *testing.T) {
+	mold := RequestMold{
+		Name: "Ping",
+		Yaml: &YamlRequest{
+			Headers:    Headers{"Accept": {"*/*"}},
+			Query:      QueryParams{"page": {"1"}},
+			PathParams: map[string]string{"id": "1"},
+			Body:       map[string]interface{}{"tags": []interface{}{"a"}},
+			Options:    map[string]interface{}{"httpClient": map[string]interface{}{"debug": true}},
+		},
+	}
+
+	clone := mold.Clone()
+	clone.Yaml.Headers["Authorization"] = HeaderValues{"Bearer token"}
+	clone.Yaml.Headers["Accept"][0] = "application/json"
+	clone.Yaml.Query["page"] = QueryValues{"2"}
+	clone.Yaml.PathParams["id"] = "2"
+	clone.Yaml.Body.(map[string]interface{})["tags"].([]interface{})[0] = "b"
+	clone.Yaml.Options["httpClient"].(map[string]interface{})["debug"] = false
+
+	assert.Equal(t, Headers{"Accept": {"*/*"}}, mold.Yaml.Headers)
+	assert.Equal(t, QueryParams{"page": {"1"}}, mold.Yaml.Query)
+	assert.Equal(t, map[string]string{"id": "1"}, mold.Yaml.PathParams)
+	assert.Equal(t, map[string]interface{}{"tags": []interface{}{"a"}}, mold.Yaml.Body)
+	assert.Equal(t, map[string]interface{}{"httpClient": map[string]interface{}{"debug": true}}, mold.Yaml.Options)
+}
@@ -2,12 +2,16 @@ package model
 
 import (
 	"encoding/json"
+	"os"
 	"time"
 )
 
 type Response struct {
-	Headers     map[string]HeaderValues
-	Body        []byte
+	Headers map[string]HeaderValues
+	Body    []byte
+	// BodyFile holds the whole body when it was larger than printer.maxBodyBytes. Body then
+	// holds only its beginning for printing; use FullBody to get all of it.
+	BodyFile    string
 	Status      string
 	StatusCode  int
 	Proto       string
@@ -18,6 +22,9 @@ type Response struct {
 	Options     map[string]interface{}
 	Request     Request
 	RequestName string
+	Redirects   []Redirect
+	Events      []Event
+	Frames      []WebSocketFrame
 }
 
 type TraceInfo struct {
@@ -33,6 +40,31 @@ type TraceInfo struct {
 	ConnIdleTime   time.Duration
 	RequestAttempt int
 	RemoteAddr     string
+	Attempts       []Attempt
+}
+
+type Attempt struct {
+	Attempt    int
+	StatusCode int
+	Status     string
+	Error      string
+	Time       time.Duration
+}
+
+type Redirect struct {
+	StatusCode int
+	Status     string
+	Url        string
+	Location   string
+	Time       time.Duration
+}
+
+// Event is a server-sent event of a text/event-stream response
+type Event struct {
+	Id    string
+	Event string
+	Data  string
+	Retry time.Duration
 }
 
 func (r *Response) HeadersAsMapString() map[string][]string {
@@ -43,8 +75,20 @@ func (r *Response) HeadersAsMapString() map[string][]string {
 	return headers
 }
 
+// FullBody returns the whole body, reading it from BodyFile when it did not fit in memory
+func (r *Response) FullBody() ([]byte, error) {
+	if len(r.BodyFile) == 0 {
+		return r.Body, nil
+	}
+	return os.ReadFile(r.BodyFile)
+}
+
 func (r *Response) BodyAsMap() (map[string]interface{}, error) {
+	body, err := r.FullBody()
+	if err != nil {
+		return nil, err
+	}
 	var bodyAsMap map[string]interface{}
-	err := json.Unmarshal(r.Body, &bodyAsMap)
+	err = json.Unmarshal(body, &bodyAsMap)
 	return bodyAsMap, err
 }
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	PROTOCOL_HTTP      = "http"
+	PROTOCOL_WEBSOCKET = "websocket"
+	WEBSOCKET_YAML_EXT = ".ws.yaml"
+)
+
+// WebSocketMessage is a scripted message of a websocket request. When Await is set, a received
+// message matching it as a regular expression is waited for before the next message is sent.
+type WebSocketMessage struct {
+	Send  string `yaml:"send"`
+	Await string `yaml:"await,omitempty"`
+}
+
+// WebSocketFrame is a message sent or received over a websocket connection
+type WebSocketFrame struct {
+	Sent bool
+	Data string
+	Time time.Time
+}
+
+// String returns the frame prefixed with its direction, > for sent and < for received
+func (f WebSocketFrame) String() string {
+	direction := "<"
+	if f.Sent {
+		direction = ">"
+	}
+	return fmt.Sprintf("%s %s", direction, f.Data)
+}
+
+// IsWebSocket tells if the mold is a websocket request either by its protocol or by its file extension
+func (r *RequestMold) IsWebSocket() bool {
+	if r.Yaml == nil {
+		return false
+	}
+	return r.Yaml.Protocol == PROTOCOL_WEBSOCKET || strings.HasSuffix(r.Filename, WEBSOCKET_YAML_EXT)
+}
@@ -0,0 +1,101 @@
+package print
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/bench"
+	"github.com/susiteemu/startpoint/tui/styles"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var benchPercentiles = []float64{50, 90, 95, 99}
+
+func SprintBenchReport(report *bench.Report, pretty bool) (string, string, error) {
+	if report == nil {
+		return "", "", errors.New("Report must not be nil!")
+	}
+
+	theme := styles.LoadTheme()
+	labelStyle := lipgloss.NewStyle().Foreground(theme.ResponseHeaderFgColor)
+
+	var builder, prettyBuilder []string
+	add := func(label string, value string, prettyValue string) {
+		builder = append(builder, fmt.Sprintf("%s: %s", label, value))
+		if pretty {
+			prettyBuilder = append(prettyBuilder, fmt.Sprintf("%s: %s", labelStyle.Render(label), prettyValue))
+		}
+	}
+	addLine := func(line string) {
+		builder = append(builder, line)
+		if pretty {
+			prettyBuilder = append(prettyBuilder, line)
+		}
+	}
+
+	add("Request", report.RequestName, report.RequestName)
+	add("Requests", fmt.Sprintf("%d", report.Total), fmt.Sprintf("%d", report.Total))
+	add("Elapsed", report.Elapsed.Round(time.Millisecond).String(), report.Elapsed.Round(time.Millisecond).String())
+	throughput := fmt.Sprintf("%.2f req/s", report.Throughput())
+	add("Throughput", throughput, throughput)
+	errorRate := fmt.Sprintf("%.2f%% (%d)", report.ErrorRate()*100, report.Errors)
+	prettyErrorRate := errorRate
+	if report.Errors > 0 {
+		prettyErrorRate = lipgloss.NewStyle().Foreground(theme.ErrorFgColor).Render(errorRate)
+	}
+	add("Errors", errorRate, prettyErrorRate)
+
+	addLine("")
+	addLine("Status codes:")
+	statusCodes := make([]int, 0, len(report.StatusCodes))
+	for k := range report.StatusCodes {
+		statusCodes = append(statusCodes, k)
+	}
+	sort.Ints(statusCodes)
+	for _, code := range statusCodes {
+		count := report.StatusCodes[code]
+		line := fmt.Sprintf("  %d: %d", code, count)
+		builder = append(builder, line)
+		if pretty {
+			prettyBuilder = append(prettyBuilder, fmt.Sprintf("  %s: %d", statusCodeStyle(code).Render(fmt.Sprintf("%d", code)), count))
+		}
+	}
+
+	addLine("")
+	header := fmt.Sprintf("%-8s", "Latency")
+	for _, p := range benchPercentiles {
+		header += fmt.Sprintf(" %10s", fmt.Sprintf("p%g", p))
+	}
+	header += fmt.Sprintf(" %10s", "max")
+	addLine(header)
+	addLine(sprintPercentiles("Total", report.Latencies))
+	for _, phase := range report.Phases {
+		addLine(sprintPercentiles(phase.Name, phase.Latencies))
+	}
+
+	prettyPrinted := ""
+	if pretty {
+		prettyPrinted = strings.Join(prettyBuilder, "\n")
+	}
+	return strings.Join(builder, "\n"), prettyPrinted, nil
+}
+
+func sprintPercentiles(name string, latencies []time.Duration) string {
+	line := fmt.Sprintf("%-8s", name)
+	for _, p := range benchPercentiles {
+		line += fmt.Sprintf(" %10s", roundLatency(bench.Percentile(latencies, p)))
+	}
+	line += fmt.Sprintf(" %10s", roundLatency(bench.Percentile(latencies, 100)))
+	return line
+}
+
+func roundLatency(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(10 * time.Microsecond).String()
+}
@@ -0,0 +1,80 @@
+package print
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/tools/sniff"
+)
+
+// maxHexDumpBytes limits how much of a binary body is dumped
+const maxHexDumpBytes = 16 * 1024
+
+// IsBinary tells if body is not text by sniffing its beginning
+func IsBinary(body []byte) bool {
+	if len(body) == 0 {
+		return false
+	}
+	return !strings.HasPrefix(sniff.ContentType(body), "text/")
+}
+
+// SprintBinaryBody prints a summary of the body's size and type followed by an xxd style hex
+// dump of its beginning
+func SprintBinaryBody(size int64, body []byte, headers model.Headers, pretty bool) (string, string) {
+	contentType := strings.Split(sniff.ContentType(body), ";")[0]
+	if declared, err := headers.ContentType(); err == nil && len(declared) > 0 && declared != contentType {
+		contentType = fmt.Sprintf("%s (sniffed %s)", declared, contentType)
+	}
+	summary := fmt.Sprintf("Binary body: %s, %d bytes", contentType, size)
+
+	dumped := body
+	if len(dumped) > maxHexDumpBytes {
+		dumped = dumped[:maxHexDumpBytes]
+	}
+	plainDump, prettyDump := hexDump(dumped)
+	plain := []string{summary, plainDump}
+	prettyLines := []string{SprintFaint(summary), prettyDump}
+	if rest := size - int64(len(dumped)); rest > 0 {
+		note := fmt.Sprintf("… %d more bytes, save the body to see them", rest)
+		plain = append(plain, note)
+		prettyLines = append(prettyLines, SprintFaint(note))
+	}
+	prettyStr := ""
+	if pretty {
+		prettyStr = strings.Join(prettyLines, "\n")
+	}
+	return strings.Join(plain, "\n"), prettyStr
+}
+
+// hexDump formats bytes like xxd: offset, 16 bytes in groups of two and their printable characters
+func hexDump(data []byte) (string, string) {
+	var plain, pretty []string
+	for offset := 0; offset < len(data); offset += 16 {
+		line := data[offset:min(offset+16, len(data))]
+
+		var hex, text strings.Builder
+		for i := 0; i < 16; i++ {
+			if i < len(line) {
+				fmt.Fprintf(&hex, "%02x", line[i])
+			} else {
+				hex.WriteString("  ")
+			}
+			if i%2 == 1 {
+				hex.WriteByte(' ')
+			}
+		}
+		for _, c := range line {
+			if c >= 0x20 && c < 0x7f {
+				text.WriteByte(c)
+			} else {
+				text.WriteByte('.')
+			}
+		}
+
+		address := fmt.Sprintf("%08x:", offset)
+		plain = append(plain, fmt.Sprintf("%s %s %s", address, hex.String(), text.String()))
+		pretty = append(pretty, fmt.Sprintf("%s %s %s", SprintFaint(address), hex.String(), SprintFaint(text.String())))
+	}
+	return strings.Join(plain, "\n"), strings.Join(pretty, "\n")
+}
@@ -0,0 +1,131 @@
+package print
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     []byte
+		expected bool
+	}{
+		{
+			name:     "Empty",
+			body:     []byte{},
+			expected: false,
+		},
+		{
+			name:     "Text",
+			body:     []byte("hello world"),
+			expected: false,
+		},
+		{
+			name:     "Json",
+			body:     []byte(`{"name": "Rex"}`),
+			expected: false,
+		},
+		{
+			name:     "Multibyte character cut at the end of sniffed bytes",
+			body:     []byte(strings.Repeat("a", 511) + "ä"),
+			expected: false,
+		},
+		{
+			name:     "Png",
+			body:     []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"),
+			expected: true,
+		},
+		{
+			name:     "Gzip",
+			body:     []byte{0x1f, 0x8b, 0x08, 0x00},
+			expected: true,
+		},
+		{
+			name:     "Control characters",
+			body:     []byte{0x00, 0x01, 0x02},
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsBinary(tt.body))
+		})
+	}
+}
+
+func TestHexDump(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{
+			name:     "Empty",
+			data:     []byte{},
+			expected: "",
+		},
+		{
+			name:     "Full line",
+			data:     []byte("0123456789abcdef"),
+			expected: "00000000: 3031 3233 3435 3637 3839 6162 6364 6566  0123456789abcdef",
+		},
+		{
+			name: "Final partial line",
+			data: []byte("0123456789abcdef\x00\x01ab"),
+			expected: "00000000: 3031 3233 3435 3637 3839 6162 6364 6566  0123456789abcdef\n" +
+				"00000010: 0001 6162                                ..ab",
+		},
+		{
+			name:     "Odd number of bytes",
+			data:     []byte{0xff, 'a', 0x7f},
+			expected: "00000000: ff61 7f                                  .a.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain, _ := hexDump(tt.data)
+			assert.Equal(t, tt.expected, plain)
+		})
+	}
+}
+
+func TestSprintBinaryBody(t *testing.T) {
+	body := []byte("\x89PNG\r\n\x1a\n")
+	tests := []struct {
+		name     string
+		size     int64
+		headers  model.Headers
+		expected string
+	}{
+		{
+			name:     "Declared content type",
+			size:     8,
+			headers:  model.Headers{"Content-Type": {"image/png"}},
+			expected: "Binary body: image/png, 8 bytes\n00000000: 8950 4e47 0d0a 1a0a                      .PNG....",
+		},
+		{
+			name:     "Content type differing from the sniffed one",
+			size:     8,
+			headers:  model.Headers{"Content-Type": {"application/octet-stream"}},
+			expected: "Binary body: application/octet-stream (sniffed image/png), 8 bytes\n00000000: 8950 4e47 0d0a 1a0a                      .PNG....",
+		},
+		{
+			name:     "Body larger than the dumped part",
+			size:     20,
+			headers:  model.Headers{},
+			expected: "Binary body: image/png, 20 bytes\n00000000: 8950 4e47 0d0a 1a0a                      .PNG....\n… 12 more bytes, save the body to see them",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain, pretty := SprintBinaryBody(tt.size, body, tt.headers, false)
+			assert.Equal(t, tt.expected, plain)
+			assert.Empty(t, pretty)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package print
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rs/zerolog/log"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+var yamlContentTypes = []string{model.CONTENT_TYPE_APPLICATION_YAML, "application/x-yaml", "text/yaml", "text/x-yaml"}
+var ndjsonContentTypes = []string{model.CONTENT_TYPE_NDJSON, "application/jsonl", "application/x-jsonlines"}
+var msgpackContentTypes = []string{model.CONTENT_TYPE_MSGPACK, "application/x-msgpack", "application/vnd.msgpack"}
+
+func isContentType(contentType string, contentTypes []string) bool {
+	return slices.Contains(contentTypes, strings.ToLower(contentType))
+}
+
+type YamlContentTypeBodyHandler struct{}
+
+func (h *YamlContentTypeBodyHandler) Supports(contentType string) bool {
+	return isContentType(contentType, yamlContentTypes)
+}
+
+func (h *YamlContentTypeBodyHandler) Handle(body []byte) (string, error) {
+	var formatted bytes.Buffer
+	decoder := yaml.NewDecoder(bytes.NewReader(body))
+	encoder := yaml.NewEncoder(&formatted)
+	encoder.SetIndent(2)
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			log.Error().Err(err).Msg("Failed to parse yaml")
+			return "", err
+		}
+		if err := encoder.Encode(&document); err != nil {
+			return "", err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(formatted.String(), "\n"), nil
+}
+
+type CsvContentTypeBodyHandler struct{}
+
+func (h *CsvContentTypeBodyHandler) Supports(contentType string) bool {
+	return strings.EqualFold(contentType, model.CONTENT_TYPE_TEXT_CSV)
+}
+
+// Handle renders the rows as a table with aligned columns and the first row as its header
+func (h *CsvContentTypeBodyHandler) Handle(body []byte) (string, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse csv")
+		return "", err
+	}
+	return sprintTable(records), nil
+}
+
+// sprintTable aligns the columns of records with the first record as the header
+func sprintTable(records [][]string) string {
+	if len(records) == 0 {
+		return ""
+	}
+
+	var widths []int
+	for _, record := range records {
+		for i, field := range record {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], runewidth.StringWidth(field))
+		}
+	}
+	separator := make([]string, len(widths))
+	for i, width := range widths {
+		separator[i] = strings.Repeat("-", max(width, 1))
+	}
+	records = slices.Insert(records, 1, separator)
+
+	var table []string
+	for _, record := range records {
+		var row strings.Builder
+		for i, field := range record {
+			if i > 0 {
+				row.WriteString("  ")
+			}
+			row.WriteString(field)
+			if i < len(record)-1 {
+				row.WriteString(strings.Repeat(" ", widths[i]-runewidth.StringWidth(field)))
+			}
+		}
+		table = append(table, row.String())
+	}
+	return strings.Join(table, "\n")
+}
+
+type FormUrlEncodedContentTypeBodyHandler struct{}
+
+func (h *FormUrlEncodedContentTypeBodyHandler) Supports(contentType string) bool {
+	return strings.EqualFold(contentType, model.CONTENT_TYPE_FORM_URLENCODED)
+}
+
+// Handle lists decoded keys and values in the order they appear in the body
+func (h *FormUrlEncodedContentTypeBodyHandler) Handle(body []byte) (string, error) {
+	type pair struct {
+		key, value string
+	}
+	var pairs []pair
+	width := 0
+	for _, field := range strings.Split(strings.TrimSpace(string(body)), "&") {
+		if len(field) == 0 {
+			continue
+		}
+		key, value, _ := strings.Cut(field, "=")
+		key, err := url.QueryUnescape(key)
+		if err != nil {
+			return "", err
+		}
+		value, err = url.QueryUnescape(value)
+		if err != nil {
+			return "", err
+		}
+		pairs = append(pairs, pair{key, value})
+		width = max(width, runewidth.StringWidth(key))
+	}
+
+	var lines []string
+	for _, p := range pairs {
+		lines = append(lines, p.key+strings.Repeat(" ", width-runewidth.StringWidth(p.key))+" = "+p.value)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+type NdjsonContentTypeBodyHandler struct{}
+
+func (h *NdjsonContentTypeBodyHandler) Supports(contentType string) bool {
+	return isContentType(contentType, ndjsonContentTypes)
+}
+
+// Handle indents each json value of the body on its own
+func (h *NdjsonContentTypeBodyHandler) Handle(body []byte) (string, error) {
+	var values []string
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var prettyJson bytes.Buffer
+		if err := json.Indent(&prettyJson, []byte(line), "", "  "); err != nil {
+			log.Error().Err(err).Msg("Failed to indent ndjson line")
+			return "", err
+		}
+		values = append(values, prettyJson.String())
+	}
+	return strings.Join(values, "\n"), nil
+}
+
+type MsgpackContentTypeBodyHandler struct{}
+
+func (h *MsgpackContentTypeBodyHandler) Supports(contentType string) bool {
+	return isContentType(contentType, msgpackContentTypes)
+}
+
+// Handle decodes each value of the body to indented json, one value per line. Binary data is
+// written as base64 and timestamps as RFC 3339 strings.
+func (h *MsgpackContentTypeBodyHandler) Handle(body []byte) (string, error) {
+	decoder := msgpack.NewDecoder(bytes.NewReader(body))
+	// keys of json objects are strings but msgpack allows any keys
+	decoder.SetMapDecoder(func(d *msgpack.Decoder) (interface{}, error) {
+		return d.DecodeUntypedMap()
+	})
+	var values []string
+	for {
+		value, err := decoder.DecodeInterface()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to decode msgpack")
+			return "", err
+		}
+		var prettyJson bytes.Buffer
+		encoder := json.NewEncoder(&prettyJson)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(toJsonValue(value)); err != nil {
+			log.Error().Err(err).Msg("Failed to encode msgpack as json")
+			return "", err
+		}
+		values = append(values, strings.TrimSuffix(prettyJson.String(), "\n"))
+	}
+	return strings.Join(values, "\n"), nil
+}
+
+// toJsonValue converts maps with keys of any type to maps with string keys
+func toJsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = toJsonValue(item)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range v {
+			v[i] = toJsonValue(item)
+		}
+		return v
+	}
+	return value
+}
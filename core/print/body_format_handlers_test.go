@@ -0,0 +1,91 @@
+package print
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestBodyFormatHandlers(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		expected    string
+		err         bool
+	}{
+		{
+			name:        "YAML",
+			contentType: "application/yaml",
+			body:        []byte("a:   1\nb:\n    - x # comment\n---\nc: 2\n"),
+			expected:    "a: 1\nb:\n  - x # comment\n---\nc: 2",
+		},
+		{
+			name:        "CSV as a table",
+			contentType: "text/csv",
+			body:        []byte("id,name,email\n1,Ålice,a@example.com\n22,Bob\n"),
+			expected:    "id  name   email\n--  -----  -------------\n1   Ålice  a@example.com\n22  Bob",
+		},
+		{
+			name:        "Form urlencoded",
+			contentType: "application/x-www-form-urlencoded",
+			body:        []byte("name=John+Doe&id=1&tags=a%26b&empty"),
+			expected:    "name  = John Doe\nid    = 1\ntags  = a&b\nempty = ",
+		},
+		{
+			name:        "NDJSON",
+			contentType: "application/x-ndjson",
+			body:        []byte("{\"a\":1}\n\n[1,2]\n"),
+			expected:    "{\n  \"a\": 1\n}\n[\n  1,\n  2\n]",
+		},
+		{
+			name:        "Invalid NDJSON",
+			contentType: "application/x-ndjson",
+			body:        []byte("{\"a\":1}\nnope\n"),
+			err:         true,
+		},
+	}
+	dispatcher := NewBodyFormatter(
+		&YamlContentTypeBodyHandler{},
+		&CsvContentTypeBodyHandler{},
+		&FormUrlEncodedContentTypeBodyHandler{},
+		&NdjsonContentTypeBodyHandler{},
+		&MsgpackContentTypeBodyHandler{},
+	)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatted, err := dispatcher.Format(tt.contentType, tt.body)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, formatted)
+		})
+	}
+}
+
+func TestMsgpackContentTypeBodyHandler(t *testing.T) {
+	var body bytes.Buffer
+	encoder := msgpack.NewEncoder(&body)
+	assert.NoError(t, encoder.Encode(map[string]interface{}{
+		"id":   300,
+		"name": "<b>",
+		"list": []interface{}{-1, 1.5, nil},
+		"bin":  []byte{1, 2},
+	}))
+	assert.NoError(t, encoder.Encode(map[int]string{1: "x"}))
+	assert.NoError(t, encoder.Encode(time.Unix(60, 0).UTC()))
+
+	handler := &MsgpackContentTypeBodyHandler{}
+	assert.True(t, handler.Supports("application/x-msgpack"))
+	formatted, err := handler.Handle(body.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n  \"bin\": \"AQI=\",\n  \"id\": 300,\n  \"list\": [\n    -1,\n    1.5,\n    null\n  ],\n  \"name\": \"<b>\"\n}\n{\n  \"1\": \"x\"\n}\n\"1970-01-01T00:01:00Z\"", formatted)
+
+	_, err = handler.Handle(body.Bytes()[:body.Len()-1])
+	assert.Error(t, err)
+}
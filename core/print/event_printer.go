@@ -0,0 +1,67 @@
+package print
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/tui/styles"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SprintEvent prints a server-sent event in the wire format of event streams. Data that is
+// valid json is highlighted when printing pretty.
+func SprintEvent(event model.Event, pretty bool) (string, string, error) {
+	var lines, prettyLines []string
+	theme := styles.LoadTheme()
+	faintStyle := lipgloss.NewStyle().Foreground(theme.TextFgColor).Faint(true)
+	fieldStyle := lipgloss.NewStyle().Foreground(theme.TextFgColor).Bold(true)
+
+	if len(event.Event) > 0 {
+		lines = append(lines, fmt.Sprintf("event: %s", event.Event))
+		if pretty {
+			prettyLines = append(prettyLines, fmt.Sprintf("%s %s", faintStyle.Render("event:"), fieldStyle.Render(event.Event)))
+		}
+	}
+	if len(event.Id) > 0 {
+		lines = append(lines, fmt.Sprintf("id: %s", event.Id))
+		if pretty {
+			prettyLines = append(prettyLines, fmt.Sprintf("%s %s", faintStyle.Render("id:"), event.Id))
+		}
+	}
+
+	for _, line := range strings.Split(event.Data, "\n") {
+		lines = append(lines, fmt.Sprintf("data: %s", line))
+	}
+	if pretty {
+		prettyData := event.Data
+		if json.Valid([]byte(event.Data)) {
+			highlighted, err := highlightJson(event.Data)
+			if err != nil {
+				return "", "", err
+			}
+			prettyData = highlighted
+		}
+		for _, line := range strings.Split(prettyData, "\n") {
+			prettyLines = append(prettyLines, fmt.Sprintf("%s %s", faintStyle.Render("data:"), line))
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n", strings.Join(prettyLines, "\n") + "\n", nil
+}
+
+func highlightJson(data string) (string, error) {
+	buf := new(bytes.Buffer)
+	iterator, err := resolveLexer("application/json").Tokenise(nil, data)
+	if err != nil {
+		return "", err
+	}
+	err = resolveFormatter().Format(buf, resolveStyle(), iterator)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
@@ -0,0 +1,31 @@
+package print
+
+import (
+	"bytes"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+func SprintGraphQL(document string) (string, error) {
+	buf := new(bytes.Buffer)
+
+	// graphql lexer has no mime type to resolve it with
+	lexer := lexers.Get("graphql")
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+	style := resolveStyle()
+	formatter := resolveFormatter()
+	iterator, err := lexer.Tokenise(nil, document)
+	if err != nil {
+		return "", err
+	}
+	err = formatter.Format(buf, style, iterator)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
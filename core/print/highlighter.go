@@ -2,8 +2,10 @@ package print
 
 import (
 	"fmt"
+	"github.com/susiteemu/startpoint/core/ansi"
 	"github.com/susiteemu/startpoint/core/configuration"
 	"regexp"
+	"strings"
 
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/formatters"
@@ -91,3 +93,35 @@ func HighlightWithRegex(text string, pattern string, baseFg lipgloss.Color, base
 	}
 	return coloredText
 }
+
+// HighlightWithRegexKeepingAnsi highlights matches of pattern in text which may already be
+// colored with ANSI sequences. Matches are searched from the text without the sequences and the
+// colors of the text continue after each match. Returns the text and the number of matches.
+func HighlightWithRegexKeepingAnsi(text string, pattern *regexp.Regexp, highlightFg lipgloss.Color, highlightBg lipgloss.Color) (string, int) {
+	plainText, positions := ansi.Strip(text)
+	matches := pattern.FindAllStringIndex(plainText, -1)
+	if len(matches) == 0 {
+		return text, 0
+	}
+
+	highlightStyle := lipgloss.NewStyle().Foreground(highlightFg).Background(highlightBg)
+	var highlighted strings.Builder
+	cursor := 0
+	count := 0
+	for _, group := range matches {
+		startIndex, endIndex := group[0], group[1]
+		if startIndex == endIndex {
+			// empty matches, e.g. of "a*", have nothing to highlight
+			continue
+		}
+		count++
+		highlighted.WriteString(text[cursor:positions[startIndex]])
+		highlighted.WriteString(highlightStyle.Render(plainText[startIndex:endIndex]))
+		// continue with the color active at the end of the match, sequences after it follow as is
+		cursor = positions[endIndex-1] + 1
+		colorState, _ := ansi.ParseANSI(text, endIndex-1)
+		highlighted.WriteString(colorState.State)
+	}
+	highlighted.WriteString(text[cursor:])
+	return highlighted.String(), count
+}
@@ -0,0 +1,64 @@
+package print
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlightWithRegexKeepingAnsi(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.ANSI)
+	defer lipgloss.SetColorProfile(termenv.Ascii)
+
+	const red = "\x1b[31m"
+	const green = "\x1b[32m"
+	const reset = "\x1b[0m"
+	const highlighted = "\x1b[30;43m"
+
+	tests := []struct {
+		name     string
+		text     string
+		pattern  string
+		expected string
+		count    int
+	}{
+		{
+			name:     "Plain text",
+			text:     "foo bar foo",
+			pattern:  "foo",
+			expected: highlighted + "foo" + reset + " bar " + highlighted + "foo" + reset,
+			count:    2,
+		},
+		{
+			name:     "Color continues after match",
+			text:     red + "foo bar" + reset,
+			pattern:  "o b",
+			expected: red + "fo" + highlighted + "o b" + reset + red + "ar" + reset,
+			count:    1,
+		},
+		{
+			name:     "Match over colors",
+			text:     red + "foo" + green + "bar" + reset,
+			pattern:  "(?i)OB",
+			expected: red + "fo" + highlighted + "ob" + reset + green + "ar" + reset,
+			count:    1,
+		},
+		{
+			name:     "Empty matches",
+			text:     red + "foo" + reset,
+			pattern:  "x*",
+			expected: red + "foo" + reset,
+			count:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, count := HighlightWithRegexKeepingAnsi(tt.text, regexp.MustCompile(tt.pattern), lipgloss.Color("0"), lipgloss.Color("3"))
+			assert.Equal(t, tt.expected, text)
+			assert.Equal(t, tt.count, count)
+		})
+	}
+}
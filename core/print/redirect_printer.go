@@ -0,0 +1,33 @@
+package print
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/tui/styles"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func SprintRedirects(redirects []model.Redirect, pretty bool) (string, string, error) {
+	if len(redirects) == 0 {
+		return "", "", nil
+	}
+	var lines, prettyLines []string
+	for _, redirect := range redirects {
+		lines = append(lines, fmt.Sprintf("%s %s -> %s (%s)", redirect.Status, redirect.Url, redirect.Location, redirect.Time))
+		if pretty {
+			theme := styles.LoadTheme()
+			faintStyle := lipgloss.NewStyle().Foreground(theme.TextFgColor).Faint(true)
+			prettyLines = append(prettyLines, fmt.Sprintf("%s %s %s %s %s",
+				statusCodeStyle(redirect.StatusCode).Render(redirect.Status),
+				faintStyle.Render(redirect.Url),
+				faintStyle.Render("->"),
+				redirect.Location,
+				faintStyle.Render(fmt.Sprintf("(%s)", redirect.Time)),
+			))
+		}
+	}
+	return strings.Join(lines, "\n"), strings.Join(prettyLines, "\n"), nil
+}
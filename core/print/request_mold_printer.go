@@ -18,6 +18,8 @@ func SprintRequestMold(m *model.RequestMold) (string, error) {
 		return SprintStarlark(m.Raw())
 	case model.CONTENT_TYPE_LUA:
 		return SprintLua(m.Raw())
+	case model.CONTENT_TYPE_GRAPHQL:
+		return SprintGraphQL(m.Raw())
 	}
 	return "", fmt.Errorf("Unknown RequestMold type %s", m.Type)
 }
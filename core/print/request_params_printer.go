@@ -0,0 +1,33 @@
+package print
+
+import (
+	"sort"
+
+	"github.com/susiteemu/startpoint/core/model"
+)
+
+// SprintParams prints path and query params of a request as a table. Values are shown decoded.
+func SprintParams(pathParams map[string]string, query model.QueryParams) string {
+	if len(pathParams) == 0 && len(query) == 0 {
+		return ""
+	}
+	records := [][]string{{"Param", "In", "Value"}}
+	for _, name := range sortedKeys(pathParams) {
+		records = append(records, []string{name, "path", pathParams[name]})
+	}
+	for _, name := range sortedKeys(query) {
+		for _, value := range query[name] {
+			records = append(records, []string{name, "query", value})
+		}
+	}
+	return sprintTable(records)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
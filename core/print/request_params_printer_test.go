@@ -0,0 +1,15 @@
+package print
+
+import (
+	"testing"
+
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSprintParams(t *testing.T) {
+	printed := SprintParams(map[string]string{"id": "42"}, model.QueryParams{"tags": {"a&b", "c"}, "q": {"x y"}})
+	assert.Equal(t, "Param  In     Value\n-----  -----  -----\nid     path   42\nq      query  x y\ntags   query  a&b\ntags   query  c", printed)
+	assert.Empty(t, SprintParams(nil, nil))
+}
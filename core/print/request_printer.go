@@ -25,7 +25,27 @@ func SprintRequest(request *model.Request, pretty bool) (string, string, error)
 	}
 	requestBuilder = append(requestBuilder, headers)
 
-	if request.IsForm() || request.IsMultipartForm() || request.HasBodyAsMap() {
+	params := SprintParams(request.PathParams, request.Query)
+	if len(params) > 0 {
+		if len(headers) > 0 {
+			requestBuilder = append(requestBuilder, "")
+		}
+		requestBuilder = append(requestBuilder, params)
+		requestBuilder = append(requestBuilder, "")
+	}
+
+	if request.IsMultipartForm() {
+		parts, err := request.MultipartParts()
+		if err != nil {
+			return "", "", err
+		}
+		for _, part := range parts {
+			requestBuilder = append(requestBuilder, sprintMultipartPart(part))
+		}
+		if len(parts) > 0 {
+			requestBuilder = append(requestBuilder, "")
+		}
+	} else if request.IsForm() || request.HasBodyAsMap() {
 		if request.Body != nil {
 			bodyAsMap, ok := request.BodyAsMap()
 			log.Debug().Msgf("Body as map %v", bodyAsMap)
@@ -72,3 +92,21 @@ func SprintRequest(request *model.Request, pretty bool) (string, string, error)
 
 	return printed, prettyPrinted, nil
 }
+
+func sprintMultipartPart(part model.MultipartPart) string {
+	value := part.Content
+	if len(part.File) > 0 {
+		value = "@" + part.File
+	}
+	var details []string
+	if len(part.ContentType) > 0 {
+		details = append(details, part.ContentType)
+	}
+	if len(part.Filename) > 0 {
+		details = append(details, "filename "+part.Filename)
+	}
+	if len(details) > 0 {
+		return fmt.Sprintf("%s: %s (%s)", part.Name, value, strings.Join(details, ", "))
+	}
+	return fmt.Sprintf("%s: %s", part.Name, value)
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"mime"
 	"strings"
 
 	"github.com/susiteemu/startpoint/core/model"
@@ -12,26 +14,54 @@ import (
 	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/rs/zerolog/log"
 	"github.com/yosssi/gohtml"
+	"golang.org/x/text/encoding/htmlindex"
 )
 
+// defaultMaxHighlightBytes is the size after which bodies are printed without highlighting
+const defaultMaxHighlightBytes = 256 * 1024
+
 func SprintBody(size int64, body []byte, headers model.Headers, pretty bool) (string, string, error) {
 	respBodyStr := ""
 	prettyRespBodyStr := ""
-	if size > 0 {
-		dispatcher := NewBodyFormatter(&JsonContentTypeBodyHandler{}, &XmlContentTypeBodyHandler{}, &HtmlContentTypeBodyHandler{}, &DefaultContentTypeBodyHandler{})
+	contentType, contentTypeErr := headers.ContentType()
+	body = decodeCharset(body, headers)
+	// msgpack is binary but it is decoded to json
+	isMsgpack := contentTypeErr == nil && isContentType(contentType, msgpackContentTypes)
+	if size > 0 && IsBinary(body) && !isMsgpack {
+		respBodyStr, prettyRespBodyStr = SprintBinaryBody(size, body, headers, pretty)
+	} else if size > 0 {
+		dispatcher := NewBodyFormatter(
+			&JsonContentTypeBodyHandler{},
+			&XmlContentTypeBodyHandler{},
+			&HtmlContentTypeBodyHandler{},
+			&YamlContentTypeBodyHandler{},
+			&CsvContentTypeBodyHandler{},
+			&FormUrlEncodedContentTypeBodyHandler{},
+			&NdjsonContentTypeBodyHandler{},
+			&MsgpackContentTypeBodyHandler{},
+			&DefaultContentTypeBodyHandler{},
+		)
 
-		contentType, err := headers.ContentType()
-		if err != nil {
-			log.Warn().Err(err).Msg("Failed to get content type")
+		var err error
+		if contentTypeErr != nil {
+			log.Warn().Err(contentTypeErr).Msg("Failed to get content type")
 			respBodyStr = string(body)
 		} else {
 			respBodyStr, err = dispatcher.Format(contentType, body)
-			if err != nil {
+			if err != nil && isMsgpack {
+				log.Warn().Err(err).Msg("Failed to decode msgpack body")
+				respBodyStr, prettyRespBodyStr = SprintBinaryBody(size, body, headers, pretty)
+				return respBodyStr, prettyRespBodyStr, nil
+			} else if err != nil {
 				log.Warn().Err(err).Msg("Failed to format body")
 				respBodyStr = string(body)
 			}
 		}
-		if pretty && len(respBodyStr) > 0 {
+		if pretty && len(respBodyStr) > maxHighlightBytes() {
+			// highlighting large bodies takes too long
+			log.Debug().Msgf("Skipping highlighting of body of %d bytes", len(respBodyStr))
+			prettyRespBodyStr = respBodyStr
+		} else if pretty && len(respBodyStr) > 0 {
 			prettyRespBodyStr, err = prettyPrintBody(respBodyStr, headers)
 			if err != nil {
 				return "", "", err
@@ -42,6 +72,34 @@ func SprintBody(size int64, body []byte, headers model.Headers, pretty bool) (st
 	return respBodyStr, prettyRespBodyStr, nil
 }
 
+// SprintBodyPreview prints the beginning of a body that was written to file because of its size.
+// The preview is printed as is since formatting a partial body would fail.
+func SprintBodyPreview(size int64, body []byte, bodyFile string, headers model.Headers, pretty bool) (string, string) {
+	note := fmt.Sprintf("… showing first %d of %d bytes, whole body is in %s", len(body), size, bodyFile)
+	body = decodeCharset(body, headers)
+	if IsBinary(body) {
+		binaryStr, prettyBinaryStr := SprintBinaryBody(size, body, headers, pretty)
+		if pretty {
+			prettyBinaryStr += "\n" + SprintFaint(note)
+		}
+		return binaryStr + "\n" + note, prettyBinaryStr
+	}
+	bodyStr := strings.ToValidUTF8(string(body), "")
+	prettyBodyStr := ""
+	if pretty {
+		prettyBodyStr = bodyStr + "\n" + SprintFaint(note)
+	}
+	return bodyStr + "\n" + note, prettyBodyStr
+}
+
+func maxHighlightBytes() int {
+	maxBytes, set := config.GetInt("printer.maxHighlightBytes")
+	if !set || maxBytes < 0 {
+		return defaultMaxHighlightBytes
+	}
+	return maxBytes
+}
+
 func prettyPrintBody(respBodyStr string, headers model.Headers) (string, error) {
 	buf := new(bytes.Buffer)
 	lexer := resolveBodyLexer(headers)
@@ -66,9 +124,22 @@ func resolveBodyLexer(headers model.Headers) chroma.Lexer {
 		lexer = lexers.Fallback
 		log.Warn().Err(err).Msgf("Failed to get content type: using fallback lexer %v", lexer)
 	} else {
-		if contentType == model.CONTENT_TYPE_PLAINTEXT {
+		switch {
+		case contentType == model.CONTENT_TYPE_PLAINTEXT:
 			lexer = lexers.Get("plaintext")
-		} else {
+		case hasStructuredSuffix(contentType, "json"):
+			lexer = lexers.Get("json")
+		case hasStructuredSuffix(contentType, "xml"):
+			lexer = lexers.Get("xml")
+		case isContentType(contentType, yamlContentTypes), hasStructuredSuffix(contentType, "yaml"):
+			lexer = lexers.Get("yaml")
+		case isContentType(contentType, ndjsonContentTypes), isContentType(contentType, msgpackContentTypes):
+			// formatted as json
+			lexer = lexers.Get("json")
+		case strings.EqualFold(contentType, model.CONTENT_TYPE_TEXT_CSV), strings.EqualFold(contentType, model.CONTENT_TYPE_FORM_URLENCODED):
+			// formatted as plain tables
+			lexer = lexers.Get("plaintext")
+		default:
 			lexer = lexers.MatchMimeType(contentType)
 		}
 		log.Debug().Msgf("Matched mimetype %s with lexer %v", contentType, lexer)
@@ -83,6 +154,40 @@ func resolveBodyLexer(headers model.Headers) chroma.Lexer {
 	return lexer
 }
 
+// hasStructuredSuffix tells if the media type has the structured syntax suffix of RFC 6839, e.g.
+// application/problem+json has suffix json
+func hasStructuredSuffix(contentType string, suffix string) bool {
+	_, subtype, found := strings.Cut(strings.ToLower(contentType), "/")
+	return found && strings.HasSuffix(subtype, "+"+suffix)
+}
+
+// decodeCharset converts body to UTF-8 from the charset given in the Content-Type header
+func decodeCharset(body []byte, headers model.Headers) []byte {
+	values := headers[model.HEADER_NAME_CONTENT_TYPE]
+	if len(values) == 0 {
+		return body
+	}
+	_, params, err := mime.ParseMediaType(values[0])
+	if err != nil {
+		return body
+	}
+	charset := strings.ToLower(params["charset"])
+	if len(charset) == 0 || charset == "utf-8" || charset == "utf8" || charset == "us-ascii" {
+		return body
+	}
+	encoding, err := htmlindex.Get(charset)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Unknown charset %s, printing body as is", charset)
+		return body
+	}
+	decoded, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to decode body from charset %s", charset)
+		return body
+	}
+	return decoded
+}
+
 type BodyFormatHandler interface {
 	Supports(contentType string) bool
 	Handle(body []byte) (string, error)
@@ -91,7 +196,7 @@ type BodyFormatHandler interface {
 type JsonContentTypeBodyHandler struct{}
 
 func (h *JsonContentTypeBodyHandler) Supports(contentType string) bool {
-	return strings.HasPrefix(strings.ToLower(contentType), model.CONTENT_TYPE_APPLICATION_JSON)
+	return strings.HasPrefix(strings.ToLower(contentType), model.CONTENT_TYPE_APPLICATION_JSON) || hasStructuredSuffix(contentType, "json")
 }
 
 func (h *JsonContentTypeBodyHandler) Handle(body []byte) (string, error) {
@@ -107,7 +212,7 @@ func (h *JsonContentTypeBodyHandler) Handle(body []byte) (string, error) {
 type XmlContentTypeBodyHandler struct{}
 
 func (h *XmlContentTypeBodyHandler) Supports(contentType string) bool {
-	return strings.HasPrefix(strings.ToLower(contentType), model.CONTENT_TYPE_APPLICATION_XML)
+	return strings.HasPrefix(strings.ToLower(contentType), model.CONTENT_TYPE_APPLICATION_XML) || hasStructuredSuffix(contentType, "xml")
 }
 
 func (h *XmlContentTypeBodyHandler) Handle(body []byte) (string, error) {
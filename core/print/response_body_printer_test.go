@@ -0,0 +1,103 @@
+package print
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSprintBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		expected    string
+	}{
+		{
+			name:        "Problem details json",
+			contentType: "application/problem+json",
+			body:        []byte(`{"title":"Not found","status":404}`),
+			expected:    "{\n  \"title\": \"Not found\",\n  \"status\": 404\n}",
+		},
+		{
+			name:        "Vendor json with parameters",
+			contentType: "application/vnd.api+json; charset=utf-8",
+			body:        []byte(`{"data":[]}`),
+			expected:    "{\n  \"data\": []\n}",
+		},
+		{
+			name:        "Atom xml",
+			contentType: "application/atom+xml",
+			body:        []byte(`<feed><title>x</title></feed>`),
+			expected:    "<feed>\n  <title>x</title>\n</feed>",
+		},
+		{
+			name:        "ISO-8859-1",
+			contentType: "text/plain; charset=ISO-8859-1",
+			body:        []byte{'p', 0xe4, 'i', 'v', 0xe4},
+			expected:    "päivä",
+		},
+		{
+			name:        "Windows-1252 json",
+			contentType: "application/json; charset=windows-1252",
+			body:        []byte{'{', '"', 'a', '"', ':', '"', 0x80, '"', '}'},
+			expected:    "{\n  \"a\": \"€\"\n}",
+		},
+		{
+			name:        "Shift_JIS",
+			contentType: "text/plain; charset=Shift_JIS",
+			body:        []byte{0x82, 0xb1, 0x82, 0xf1, 0x82, 0xc9, 0x82, 0xbf, 0x82, 0xcd},
+			expected:    "こんにちは",
+		},
+		{
+			name:        "Unknown charset",
+			contentType: "text/plain; charset=nope",
+			body:        []byte("as is"),
+			expected:    "as is",
+		},
+		{
+			name:        "Binary",
+			contentType: "image/png",
+			body:        []byte("\x89PNG\r\n\x1a\n"),
+			expected:    "Binary body: image/png, 8 bytes\n00000000: 8950 4e47 0d0a 1a0a                      .PNG....",
+		},
+		{
+			name:        "Empty",
+			contentType: "application/octet-stream",
+			body:        []byte{},
+			expected:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := model.Headers{model.HEADER_NAME_CONTENT_TYPE: {tt.contentType}}
+			printed, _, err := SprintBody(int64(len(tt.body)), tt.body, headers, false)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, printed)
+		})
+	}
+}
+
+func TestSprintResponseWithBodyFile(t *testing.T) {
+	bodyFile := filepath.Join(t.TempDir(), "body.txt")
+	assert.NoError(t, os.WriteFile(bodyFile, []byte("first line\nsecond line"), 0o644))
+	resp := &model.Response{
+		Headers:  map[string]model.HeaderValues{"Content-Type": {"text/plain"}},
+		Body:     []byte("first"),
+		BodyFile: bodyFile,
+		Size:     22,
+	}
+
+	printed, _, err := SprintResponse(resp, PrintOpts{PrintBody: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "first line\nsecond line", printed)
+
+	printed, _, err = SprintResponse(resp, PrintOpts{PrintBody: true, PreviewBody: true})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(printed, "first\n… showing first 5 of 22 bytes"), printed)
+}
@@ -12,6 +12,9 @@ type PrintOpts struct {
 	PrintBody      bool
 	PrintTraceInfo bool
 	PrintRequest   bool
+	// PreviewBody prints only the beginning of a body that was written to file because of its
+	// size instead of reading the whole body from the file
+	PreviewBody bool
 }
 
 func SprintResponse(resp *model.Response, printOpts PrintOpts) (string, string, error) {
@@ -88,6 +91,16 @@ func sprintResponse(resp *model.Response, printOpts PrintOpts) (string, string,
 	}
 
 	if printOpts.PrintHeaders {
+		redirectsStr, prettyRedirectsStr, err := SprintRedirects(resp.Redirects, pretty)
+		if err != nil {
+			return "", "", err
+		}
+		if len(redirectsStr) > 0 {
+			responseBuilder = append(responseBuilder, redirectsStr)
+		}
+		if len(prettyRedirectsStr) > 0 && printOpts.PrettyPrint {
+			prettyResponseBuilder = append(prettyResponseBuilder, prettyRedirectsStr)
+		}
 		respStatusStr, prettyRespStatusStr, err := SprintStatus(resp, pretty)
 		if err != nil {
 			return "", "", err
@@ -109,9 +122,18 @@ func sprintResponse(resp *model.Response, printOpts PrintOpts) (string, string,
 	}
 
 	if printOpts.PrintBody {
-		respBodyStr, prettyRespBodyStr, err := SprintBody(resp.Size, resp.Body, resp.Headers, pretty)
-		if err != nil {
-			return "", "", err
+		var respBodyStr, prettyRespBodyStr string
+		if len(resp.BodyFile) > 0 && printOpts.PreviewBody {
+			respBodyStr, prettyRespBodyStr = SprintBodyPreview(resp.Size, resp.Body, resp.BodyFile, resp.Headers, pretty)
+		} else {
+			body, err := resp.FullBody()
+			if err != nil {
+				return "", "", err
+			}
+			respBodyStr, prettyRespBodyStr, err = SprintBody(resp.Size, body, resp.Headers, pretty)
+			if err != nil {
+				return "", "", err
+			}
 		}
 
 		if len(respBodyStr) > 0 {
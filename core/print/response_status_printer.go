@@ -15,23 +15,25 @@ func SprintStatus(resp *model.Response, pretty bool) (string, string, error) {
 		return "", "", errors.New("Response must not be nil!")
 	}
 	theme := styles.LoadTheme()
-	protoStyle := lipgloss.NewStyle()
-	statusStyle := lipgloss.NewStyle()
 	status := fmt.Sprintf("%v %v", resp.Proto, resp.Status)
 	prettyStatus := ""
 	if pretty {
-		protoStyle = lipgloss.NewStyle().Foreground(theme.ResponseProtoFgColor)
-		if resp.StatusCode < 300 {
-			statusStyle = lipgloss.NewStyle().Foreground(theme.ResponseStatus200FgColor)
-		} else if resp.StatusCode < 400 {
-			statusStyle = lipgloss.NewStyle().Foreground(theme.ResponseStatus300FgColor)
-		} else if resp.StatusCode < 500 {
-			statusStyle = lipgloss.NewStyle().Foreground(theme.ResponseStatus400FgColor)
-		} else {
-			statusStyle = lipgloss.NewStyle().Foreground(theme.ResponseStatus500FgColor)
-		}
+		protoStyle := lipgloss.NewStyle().Foreground(theme.ResponseProtoFgColor)
+		statusStyle := statusCodeStyle(resp.StatusCode)
 		prettyStatus = fmt.Sprintf("%v %v", protoStyle.Render(resp.Proto), statusStyle.Render(resp.Status))
 	}
 
 	return status, prettyStatus, nil
 }
+
+func statusCodeStyle(statusCode int) lipgloss.Style {
+	theme := styles.LoadTheme()
+	if statusCode < 300 {
+		return lipgloss.NewStyle().Foreground(theme.ResponseStatus200FgColor)
+	} else if statusCode < 400 {
+		return lipgloss.NewStyle().Foreground(theme.ResponseStatus300FgColor)
+	} else if statusCode < 500 {
+		return lipgloss.NewStyle().Foreground(theme.ResponseStatus400FgColor)
+	}
+	return lipgloss.NewStyle().Foreground(theme.ResponseStatus500FgColor)
+}
@@ -2,6 +2,7 @@ package print
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/susiteemu/startpoint/core/model"
 	"github.com/susiteemu/startpoint/tui/styles"
@@ -26,6 +27,17 @@ RemoteAddr: %s
 		traceInfo.DNSLookup, traceInfo.ConnTime, traceInfo.TCPConnTime, traceInfo.TLSHandshake, traceInfo.ServerTime, traceInfo.ResponseTime, traceInfo.TotalTime, traceInfo.IsConnReused, traceInfo.IsConnWasIdle, traceInfo.ConnIdleTime, traceInfo.RequestAttempt, traceInfo.RemoteAddr,
 	)
 
+	if len(traceInfo.Attempts) > 0 {
+		ti = strings.TrimRight(ti, " \t\n") + "\nAttempts:\n"
+		for _, attempt := range traceInfo.Attempts {
+			outcome := attempt.Status
+			if len(attempt.Error) > 0 {
+				outcome = attempt.Error
+			}
+			ti += fmt.Sprintf("  #%d %s (%s)\n", attempt.Attempt, outcome, attempt.Time)
+		}
+	}
+
 	prettyTi := ""
 	if pretty {
 		theme := styles.LoadTheme()
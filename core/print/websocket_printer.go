@@ -0,0 +1,36 @@
+package print
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/tui/styles"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SprintWebSocketFrame prints a websocket message with its direction. Data that is valid json is
+// highlighted when printing pretty.
+func SprintWebSocketFrame(frame model.WebSocketFrame, pretty bool) (string, string, error) {
+	plain := frame.String()
+	if !pretty {
+		return plain, "", nil
+	}
+
+	theme := styles.LoadTheme()
+	faintStyle := lipgloss.NewStyle().Foreground(theme.TextFgColor).Faint(true)
+	direction := lipgloss.NewStyle().Foreground(theme.ResponseStatus200FgColor).Bold(true).Render("<")
+	if frame.Sent {
+		direction = lipgloss.NewStyle().Foreground(theme.ResponseProtoFgColor).Bold(true).Render(">")
+	}
+	data := frame.Data
+	if json.Valid([]byte(data)) {
+		highlighted, err := highlightJson(data)
+		if err != nil {
+			return "", "", err
+		}
+		data = highlighted
+	}
+	return plain, fmt.Sprintf("%s %s %s", faintStyle.Render(frame.Time.Format("15:04:05")), direction, data), nil
+}
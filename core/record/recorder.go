@@ -0,0 +1,211 @@
+package record
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/tools/paths"
+	"github.com/susiteemu/startpoint/core/writer"
+
+	"github.com/rs/zerolog/log"
+)
+
+// headers that are either hop-by-hop or set by the http client itself
+var skippedHeaders = []string{
+	"Accept-Encoding",
+	"Connection",
+	"Content-Length",
+	"Host",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Proto",
+}
+
+type Recorder struct {
+	target     *url.URL
+	root       string
+	variables  []variable
+	proxy      *httputil.ReverseProxy
+	mu         sync.Mutex
+	recorded   map[string]bool
+	recordedCb func(mold *model.RequestMold, err error)
+}
+
+type variable struct {
+	name  string
+	value string
+}
+
+// New creates a reverse proxy to target that writes each distinct request as a yaml request under root.
+// Values of the given profile variables are replaced with template variables in recorded requests.
+func New(target string, root string, profile *model.Profile, recordedCb func(mold *model.RequestMold, err error)) (*Recorder, error) {
+	targetUrl, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	if targetUrl.Scheme == "" || targetUrl.Host == "" {
+		return nil, fmt.Errorf("target %s must be an absolute url", target)
+	}
+
+	r := &Recorder{
+		target:     targetUrl,
+		root:       root,
+		variables:  resolveVariables(profile),
+		recorded:   map[string]bool{},
+		recordedCb: recordedCb,
+	}
+	r.proxy = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(targetUrl)
+		},
+	}
+	return r, nil
+}
+
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to read request body")
+			http.Error(w, "Failed to read request body", http.StatusBadGateway)
+			return
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	r.record(req, body)
+	r.proxy.ServeHTTP(w, req)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) {
+	key := fmt.Sprintf("%s %s", req.Method, req.URL.Path)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.recorded[key] {
+		return
+	}
+	r.recorded[key] = true
+
+	mold := r.toRequestMold(req, body)
+	path := filepath.Join(mold.Root, mold.Filename)
+	if _, err := os.Stat(path); err == nil {
+		log.Info().Msgf("Request %s already exists: not overwriting it", path)
+		return
+	}
+	_, err := writer.WriteFile(path, mold.Raw())
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to write recorded request %s", path)
+	}
+	if r.recordedCb != nil {
+		r.recordedCb(mold, err)
+	}
+}
+
+func (r *Recorder) toRequestMold(req *http.Request, body []byte) *model.RequestMold {
+	targetUrl := *r.target
+	targetUrl.Path = singleJoiningSlash(r.target.Path, req.URL.Path)
+	targetUrl.RawQuery = req.URL.RawQuery
+
+	headers := model.Headers{}
+	for k, v := range req.Header {
+		if isSkippedHeader(k) {
+			continue
+		}
+		values := model.HeaderValues{}
+		for _, hv := range v {
+			values = append(values, r.templatize(hv))
+		}
+		headers[k] = values
+	}
+
+	yamlRequest := model.YamlRequest{
+		Url:     r.templatize(targetUrl.String()),
+		Method:  req.Method,
+		Headers: headers,
+	}
+	if len(body) > 0 {
+		yamlRequest.Body = r.templatize(string(body))
+	}
+
+	name := paths.SanitizeFileName(fmt.Sprintf("%s %s", req.Method, strings.Trim(req.URL.Path, "/")))
+	name = strings.TrimSpace(name)
+	return &model.RequestMold{
+		Yaml:     &yamlRequest,
+		Type:     model.CONTENT_TYPE_YAML,
+		Root:     r.root,
+		Name:     name,
+		Filename: fmt.Sprintf("%s.yaml", name),
+	}
+}
+
+// templatize replaces known profile values with template variables, e.g. https://api.example/foo -> {domain}/foo
+func (r *Recorder) templatize(s string) string {
+	for _, v := range r.variables {
+		s = strings.ReplaceAll(s, v.value, fmt.Sprintf("{%s}", v.name))
+	}
+	return s
+}
+
+func resolveVariables(profile *model.Profile) []variable {
+	variables := []variable{}
+	if profile == nil {
+		return variables
+	}
+	for k, v := range profile.Variables {
+		// short values would match too eagerly
+		if len(strings.TrimSpace(v)) < 3 {
+			continue
+		}
+		variables = append(variables, variable{name: k, value: v})
+	}
+	// replace longest values first so that e.g. full base url wins over its host part
+	sort.SliceStable(variables, func(i, j int) bool {
+		if len(variables[i].value) != len(variables[j].value) {
+			return len(variables[i].value) > len(variables[j].value)
+		}
+		return variables[i].name < variables[j].name
+	})
+	return variables
+}
+
+func isSkippedHeader(name string) bool {
+	for _, h := range skippedHeaders {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func singleJoiningSlash(a, b string) string {
+	aslash := strings.HasSuffix(a, "/")
+	bslash := strings.HasPrefix(b, "/")
+	switch {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash:
+		return a + "/" + b
+	}
+	return a + b
+}
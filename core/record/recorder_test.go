@@ -0,0 +1,68 @@
+package record
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/susiteemu/startpoint/core/loader"
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(body)))
+	}))
+	defer target.Close()
+
+	root := t.TempDir()
+	profile := &model.Profile{
+		Variables: map[string]string{
+			"domain": target.URL,
+			"token":  "secret-token",
+		},
+	}
+	recorded := 0
+	recorder, err := New(target.URL, root, profile, func(mold *model.RequestMold, err error) {
+		assert.Nil(t, err)
+		recorded++
+	})
+	assert.Nil(t, err)
+
+	proxy := httptest.NewServer(recorder)
+	defer proxy.Close()
+
+	req, _ := http.NewRequest("POST", proxy.URL+"/pets?limit=10", strings.NewReader(`{"name": "Fluffy"}`))
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, `POST /pets {"name": "Fluffy"}`, string(body))
+
+	// same method and path is recorded only once
+	resp, err = http.Post(proxy.URL+"/pets", "application/json", strings.NewReader("{}"))
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 1, recorded)
+
+	_, err = os.Stat(filepath.Join(root, "POST pets.yaml"))
+	assert.Nil(t, err)
+
+	mold, err := loader.ReadRequest(root, "POST pets.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, "{domain}/pets?limit=10", mold.Yaml.Url)
+	assert.Equal(t, "POST", mold.Yaml.Method)
+	assert.Equal(t, model.HeaderValues{"Bearer {token}"}, mold.Yaml.Headers["Authorization"])
+	assert.Equal(t, model.HeaderValues{"application/json"}, mold.Yaml.Headers["Content-Type"])
+	assert.Equal(t, `{"name": "Fluffy"}`, mold.Yaml.Body)
+}
@@ -2,24 +2,28 @@ package luang
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/susiteemu/startpoint/core/model"
 
 	"github.com/rs/zerolog/log"
 	conv "github.com/susiteemu/startpoint/core/tools/conv"
+	"github.com/susiteemu/startpoint/core/tools/paths"
 	"github.com/yuin/gluamapper"
 	lua "github.com/yuin/gopher-lua"
 	luar "layeh.com/gopher-luar"
 )
 
 type result struct {
-	Url     string
-	Method  string
-	Headers map[string]interface{}
-	Body    interface{}
-	Auth    map[string]interface{}
-	Options map[string]interface{}
-	Output  string
+	Url        string
+	Method     string
+	Headers    map[string]interface{}
+	Query      map[string]interface{}
+	PathParams map[string]interface{} `gluamapper:"path_params"`
+	Body       interface{}
+	Auth       map[string]interface{}
+	Options    map[string]interface{}
+	Output     string
 }
 
 func RunLuaScript(request model.RequestMold, previousResponse *model.Response) (map[string]interface{}, error) {
@@ -31,15 +35,21 @@ func RunLuaScript(request model.RequestMold, previousResponse *model.Response) (
 		headers := previousResponse.HeadersAsMapString()
 		prevResponseMap["headers"] = headers
 
+		body, err := previousResponse.FullBody()
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to read body of previous response from %s", previousResponse.BodyFile)
+			return nil, err
+		}
 		bodyAsMap, err := previousResponse.BodyAsMap()
 		if err == nil {
 			prevResponseMap["body"] = bodyAsMap
 		} else {
-			prevResponseMap["body"] = string(previousResponse.Body)
+			prevResponseMap["body"] = string(body)
 		}
 	}
 	prevResponse := luar.New(L, prevResponseMap)
 	L.SetGlobal("prevResponse", prevResponse)
+	L.SetGlobal("file", L.NewFunction(readFile(request.Root)))
 
 	if err := L.DoString(request.Scriptable.Script); err != nil {
 		log.Error().Err(err).Msg("Running Lua script resulted to error")
@@ -66,6 +76,12 @@ func RunLuaScript(request model.RequestMold, previousResponse *model.Response) (
 		values["url"] = res.Url
 		values["method"] = res.Method
 		values["headers"] = res.Headers
+		if res.Query != nil {
+			values["query"] = res.Query
+		}
+		if res.PathParams != nil {
+			values["path_params"] = res.PathParams
+		}
 		values["body"] = res.Body
 		if res.Auth == nil {
 			res.Auth = map[string]interface{}{}
@@ -79,3 +95,18 @@ func RunLuaScript(request model.RequestMold, previousResponse *model.Response) (
 
 	return values, nil
 }
+
+// readFile returns file(path) function which reads a file relative to the workspace as a string
+func readFile(root string) lua.LGFunction {
+	return func(L *lua.LState) int {
+		path := paths.Resolve(root, L.CheckString(1))
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to read file %s", path)
+			L.RaiseError("failed to read file %s: %v", path, err)
+			return 0
+		}
+		L.Push(lua.LString(content))
+		return 1
+	}
+}
@@ -2,9 +2,12 @@ package starlarkng
 
 import (
 	"errors"
+	"os"
+
 	"github.com/susiteemu/startpoint/core/model"
 	"github.com/susiteemu/startpoint/core/scripting/starlark/goconv"
 	"github.com/susiteemu/startpoint/core/scripting/starlark/starlarkconv"
+	"github.com/susiteemu/startpoint/core/tools/paths"
 
 	"github.com/rs/zerolog/log"
 	"go.starlark.net/starlark"
@@ -39,7 +42,12 @@ func RunStarlarkScript(request model.RequestMold, previousResponse *model.Respon
 			log.Debug().Msgf("previousResponseBody %v", prevResponseBody)
 		} else {
 			log.Warn().Err(err).Msgf("Could not convert body to map. Setting body as string to previous response.")
-			prevResponseBody, err := starlarkconv.Convert(string(previousResponse.Body))
+			body, err := previousResponse.FullBody()
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to read body of previous response from %s", previousResponse.BodyFile)
+				return nil, err
+			}
+			prevResponseBody, err := starlarkconv.Convert(string(body))
 			if err != nil {
 				return nil, err
 			}
@@ -51,6 +59,7 @@ func RunStarlarkScript(request model.RequestMold, previousResponse *model.Respon
 
 	predeclared := starlark.StringDict{
 		"prevResponse": &previousResponseStarlark,
+		"file":         starlark.NewBuiltin("file", readFile(request.Root)),
 	}
 
 	thread := &starlark.Thread{Name: "starlark runner thread"}
@@ -89,3 +98,20 @@ func RunStarlarkScript(request model.RequestMold, previousResponse *model.Respon
 
 	return values, nil
 }
+
+// readFile returns file(path) builtin which reads a file relative to the workspace as a string
+func readFile(root string) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var path string
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "path", &path); err != nil {
+			return nil, err
+		}
+		path = paths.Resolve(root, path)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to read file %s", path)
+			return nil, err
+		}
+		return starlark.String(content), nil
+	}
+}
@@ -0,0 +1,252 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/tools/paths"
+
+	"github.com/rs/zerolog/log"
+)
+
+const SESSIONS_DIR = ".startpoint/sessions"
+
+// capturedHeaders are request headers remembered by the session per host
+var capturedHeaders = []string{
+	model.HEADER_NAME_AUTHORIZATION,
+	"Proxy-Authorization",
+}
+
+type Cookie struct {
+	Url      string    `json:"url"`
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Path     string    `json:"path,omitempty"`
+	Domain   string    `json:"domain,omitempty"`
+	Expires  time.Time `json:"expires,omitempty"`
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"httpOnly,omitempty"`
+}
+
+// Session holds cookies over a request chain. Named sessions also capture auth headers, are
+// persisted under workspace and can be continued in later runs.
+type Session struct {
+	Name          string                       `json:"name"`
+	Headers       map[string]map[string]string `json:"headers,omitempty"`
+	StoredCookies []Cookie                     `json:"cookies,omitempty"`
+
+	root string
+	jar  *cookiejar.Jar
+	mu   sync.Mutex
+}
+
+// New creates an in-memory session living as long as the returned value. It shares only
+// cookies between requests.
+func New() *Session {
+	jar, _ := cookiejar.New(nil)
+	return &Session{
+		Headers: map[string]map[string]string{},
+		jar:     jar,
+	}
+}
+
+// Load reads named session from workspace. Returns a new empty session with the name
+// if the session has not been saved yet.
+func Load(root, name string) (*Session, error) {
+	if len(name) == 0 {
+		return nil, errors.New("session name must not be empty")
+	}
+	s := New()
+	s.Name = name
+	s.root = root
+
+	file, err := os.ReadFile(sessionPath(root, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, err
+	}
+	err = json.Unmarshal(file, s)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to unmarshal session %s", name)
+		return nil, err
+	}
+	if s.Headers == nil {
+		s.Headers = map[string]map[string]string{}
+	}
+
+	// replay stored cookies to the jar
+	cookies := s.StoredCookies
+	s.StoredCookies = nil
+	for _, c := range cookies {
+		u, err := url.Parse(c.Url)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Skipping cookie %s with invalid url %s", c.Name, c.Url)
+			continue
+		}
+		if !c.Expires.IsZero() && c.Expires.Before(time.Now()) {
+			continue
+		}
+		s.SetCookies(u, []*http.Cookie{{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			Expires:  c.Expires,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+		}})
+	}
+	return s, nil
+}
+
+// Save writes a named session to workspace. In-memory sessions are not saved.
+func (s *Session) Save() error {
+	if len(s.Name) == 0 || len(s.root) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	contents, err := json.MarshalIndent(s, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(s.root, SESSIONS_DIR)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to create sessions directory %s", dir)
+		return err
+	}
+	// session contains credentials so keep it private
+	return os.WriteFile(sessionPath(s.root, s.Name), contents, 0600)
+}
+
+// SetCookies implements http.CookieJar.
+func (s *Session) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	s.jar.SetCookies(u, cookies)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// path is kept for cookies without explicit path
+	origin := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+	for _, c := range cookies {
+		s.StoredCookies = removeCookie(s.StoredCookies, origin, c)
+		if c.MaxAge < 0 || (!c.Expires.IsZero() && c.Expires.Before(time.Now())) {
+			continue
+		}
+		expires := c.Expires
+		if c.MaxAge > 0 {
+			expires = time.Now().Add(time.Duration(c.MaxAge) * time.Second)
+		}
+		s.StoredCookies = append(s.StoredCookies, Cookie{
+			Url:      origin,
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			Expires:  expires,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+		})
+	}
+}
+
+// Cookies implements http.CookieJar.
+func (s *Session) Cookies(u *url.URL) []*http.Cookie {
+	return s.jar.Cookies(u)
+}
+
+// Apply sets the session as cookie jar of the request. Named sessions also add captured
+// headers of the request host that the request does not define itself.
+func (s *Session) Apply(request *model.Request) {
+	request.CookieJar = s
+	if len(s.Name) == 0 {
+		return
+	}
+	host, ok := hostOf(request.Url)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	headers, found := s.Headers[host]
+	if !found {
+		return
+	}
+	if request.Headers == nil {
+		request.Headers = model.Headers{}
+	}
+	for name, value := range headers {
+		if !hasHeader(request.Headers, name) {
+			request.Headers[name] = model.HeaderValues{value}
+		}
+	}
+}
+
+// Capture remembers auth headers of a sent request for later requests to the same host.
+// Only named sessions capture headers so that credentials are not passed on implicitly.
+func (s *Session) Capture(request model.Request) {
+	if len(s.Name) == 0 {
+		return
+	}
+	host, ok := hostOf(request.Url)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, values := range request.Headers {
+		for _, captured := range capturedHeaders {
+			if strings.EqualFold(name, captured) && len(values) > 0 {
+				if s.Headers[host] == nil {
+					s.Headers[host] = map[string]string{}
+				}
+				s.Headers[host][captured] = values[0]
+			}
+		}
+	}
+}
+
+func removeCookie(cookies []Cookie, origin string, c *http.Cookie) []Cookie {
+	var kept []Cookie
+	for _, existing := range cookies {
+		if existing.Url == origin && existing.Name == c.Name && existing.Path == c.Path && existing.Domain == c.Domain {
+			continue
+		}
+		kept = append(kept, existing)
+	}
+	return kept
+}
+
+func hasHeader(headers model.Headers, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostOf(rawUrl string) (string, bool) {
+	u, err := url.Parse(rawUrl)
+	if err != nil || len(u.Host) == 0 {
+		return "", false
+	}
+	return u.Host, true
+}
+
+func sessionPath(root, name string) string {
+	// name comes from the command line so keep it inside the sessions directory
+	return filepath.Join(root, SESSIONS_DIR, paths.SanitizeFileName(name)+".json")
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	root := t.TempDir()
+	u, _ := url.Parse("http://localhost:8000/login")
+
+	s, err := Load(root, "work")
+	assert.Nil(t, err)
+	s.SetCookies(u, []*http.Cookie{
+		{Name: "sid", Value: "abc", Path: "/"},
+		{Name: "old", Value: "x", Path: "/", Expires: time.Now().Add(-time.Hour)},
+	})
+	s.Capture(model.Request{
+		Url:     "http://localhost:8000/login",
+		Headers: model.Headers{"authorization": {"Bearer token"}, "Accept": {"*/*"}},
+	})
+	assert.Nil(t, s.Save())
+
+	loaded, err := Load(root, "work")
+	assert.Nil(t, err)
+	me, _ := url.Parse("http://localhost:8000/me")
+	cookies := loaded.Cookies(me)
+	assert.Equal(t, 1, len(cookies))
+	assert.Equal(t, "sid", cookies[0].Name)
+	assert.Equal(t, "abc", cookies[0].Value)
+
+	request := model.Request{Url: "http://localhost:8000/me"}
+	loaded.Apply(&request)
+	assert.Equal(t, model.HeaderValues{"Bearer token"}, request.Headers["Authorization"])
+	assert.NotNil(t, request.CookieJar)
+
+	own := model.Request{Url: "http://localhost:8000/me", Headers: model.Headers{"Authorization": {"Basic xyz"}}}
+	loaded.Apply(&own)
+	assert.Equal(t, model.HeaderValues{"Basic xyz"}, own.Headers["Authorization"])
+
+	other := model.Request{Url: "http://example.com/me"}
+	loaded.Apply(&other)
+	assert.Nil(t, other.Headers)
+}
+
+func TestSetCookiesRemovesDeleted(t *testing.T) {
+	s := New()
+	u, _ := url.Parse("http://localhost:8000/")
+	s.SetCookies(u, []*http.Cookie{{Name: "sid", Value: "abc", Path: "/"}})
+	s.SetCookies(u, []*http.Cookie{{Name: "sid", Value: "def", Path: "/"}})
+	assert.Equal(t, 1, len(s.StoredCookies))
+	assert.Equal(t, "def", s.StoredCookies[0].Value)
+
+	s.SetCookies(u, []*http.Cookie{{Name: "sid", Path: "/", MaxAge: -1}})
+	assert.Equal(t, 0, len(s.StoredCookies))
+	assert.Equal(t, 0, len(s.Cookies(u)))
+}
+
+func TestUnnamedSessionDoesNotCaptureHeaders(t *testing.T) {
+	s := New()
+	s.Capture(model.Request{
+		Url:     "http://localhost:8000/login",
+		Headers: model.Headers{"Authorization": {"Bearer token"}},
+	})
+	request := model.Request{Url: "http://localhost:8000/me"}
+	s.Apply(&request)
+	assert.Nil(t, request.Headers)
+	assert.NotNil(t, request.CookieJar)
+}
+
+func TestSaveKeepsSessionInsideSessionsDir(t *testing.T) {
+	root := t.TempDir()
+	s, err := Load(root, "../../work")
+	assert.Nil(t, err)
+	assert.Nil(t, s.Save())
+
+	entries, err := os.ReadDir(filepath.Join(root, SESSIONS_DIR))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, ".._.._work.json", entries[0].Name())
+}
@@ -0,0 +1,109 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/susiteemu/startpoint/core/model"
+)
+
+const (
+	CONTENT_TYPE_EVENT_STREAM = "text/event-stream"
+	HEADER_NAME_LAST_EVENT_ID = "Last-Event-ID"
+)
+
+// Parser reads events of a text/event-stream as specified in
+// https://html.spec.whatwg.org/multipage/server-sent-events.html. Last event id and
+// reconnection time persist across streams so that the same parser can be used when reconnecting.
+type Parser struct {
+	LastEventId string
+	Retry       time.Duration
+}
+
+// Parse reads events from stream until it ends, calling onEvent for each dispatched event.
+// Returns nil when the stream ended normally.
+func (p *Parser) Parse(stream io.Reader, onEvent func(model.Event)) error {
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	scanner.Split(scanLines)
+
+	var data strings.Builder
+	eventType := ""
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\uFEFF")
+			first = false
+		}
+
+		if len(line) == 0 {
+			if data.Len() > 0 {
+				onEvent(model.Event{
+					Id:    p.LastEventId,
+					Event: eventType,
+					Data:  strings.TrimSuffix(data.String(), "\n"),
+					Retry: p.Retry,
+				})
+			}
+			data.Reset()
+			eventType = ""
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// comment, often used as a keep-alive
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteString("\n")
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				p.LastEventId = value
+			}
+		case "retry":
+			if millis, err := strconv.Atoi(value); err == nil && millis >= 0 && !strings.ContainsAny(value, "+-") {
+				p.Retry = time.Duration(millis) * time.Millisecond
+			}
+		}
+	}
+	// an event not terminated by an empty line is discarded
+	return scanner.Err()
+}
+
+// scanLines splits on \r\n, \n and \r
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// need more data to know if \r is followed by \n
+		return 0, nil, nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
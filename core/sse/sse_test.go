@@ -0,0 +1,67 @@
+package sse
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/susiteemu/startpoint/core/model"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name           string
+		stream         string
+		expected       []model.Event
+		expectedLastId string
+		expectedRetry  time.Duration
+	}{
+		{
+			name:     "Data only",
+			stream:   "data: hello\n\n",
+			expected: []model.Event{{Data: "hello"}},
+		},
+		{
+			name:           "Multiline data, event type and id",
+			stream:         "event: update\nid: 1\ndata: first\ndata: second\n\n",
+			expected:       []model.Event{{Id: "1", Event: "update", Data: "first\nsecond"}},
+			expectedLastId: "1",
+		},
+		{
+			name:           "Id persists and comments are skipped",
+			stream:         ": keep-alive\nid: 7\ndata: a\n\ndata: b\n\n",
+			expected:       []model.Event{{Id: "7", Data: "a"}, {Id: "7", Data: "b"}},
+			expectedLastId: "7",
+		},
+		{
+			name:          "Retry and crlf line endings",
+			stream:        "retry: 1500\r\n\r\ndata:no space\r\rdata: x\r\n\r\n",
+			expected:      []model.Event{{Data: "no space", Retry: 1500 * time.Millisecond}, {Data: "x", Retry: 1500 * time.Millisecond}},
+			expectedRetry: 1500 * time.Millisecond,
+		},
+		{
+			name:     "Invalid retry is ignored",
+			stream:   "retry: +10\ndata\n\n",
+			expected: []model.Event{{Data: ""}},
+		},
+		{
+			name:     "Unterminated event is discarded",
+			stream:   "data: done\n\ndata: partial",
+			expected: []model.Event{{Data: "done"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := Parser{}
+			var events []model.Event
+			err := parser.Parse(strings.NewReader(tt.stream), func(e model.Event) {
+				events = append(events, e)
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, events)
+			assert.Equal(t, tt.expectedLastId, parser.LastEventId)
+			assert.Equal(t, tt.expectedRetry, parser.Retry)
+		})
+	}
+}
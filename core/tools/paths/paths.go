@@ -3,6 +3,7 @@ package paths
 import (
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -27,3 +28,25 @@ func ShortenPath(path string) string {
 	// Join the shortened parts back together
 	return strings.Join(parts, string(filepath.Separator))
 }
+
+func SanitizeFileName(fileName string) string {
+	// Define a regular expression to match invalid file name characters
+	reg := regexp.MustCompile(`[<>:"/\\|?*\x00-\x1F]`)
+
+	// Replace invalid characters with an underscore
+	safeFileName := reg.ReplaceAllString(fileName, "_")
+
+	// Additional replacement for Windows reserved names (optional)
+	reservedNames := regexp.MustCompile(`^(CON|PRN|AUX|NUL|COM\d|LPT\d)(\..*)?$`)
+	safeFileName = reservedNames.ReplaceAllString(safeFileName, "reserved_$1")
+
+	return safeFileName
+}
+
+// Resolve makes a relative path relative to root. Absolute paths are returned as is.
+func Resolve(root, path string) string {
+	if filepath.IsAbs(path) || len(root) == 0 {
+		return path
+	}
+	return filepath.Join(root, path)
+}
@@ -0,0 +1,33 @@
+package sniff
+
+import (
+	"io"
+	"net/http"
+	"os"
+)
+
+// SNIFF_LENGTH is the amount of bytes http.DetectContentType considers
+const SNIFF_LENGTH = 512
+
+// ContentType detects content type of data from its beginning
+func ContentType(data []byte) string {
+	if len(data) > SNIFF_LENGTH {
+		data = data[:SNIFF_LENGTH]
+	}
+	return http.DetectContentType(data)
+}
+
+// FileContentType detects content type of a file reading only its beginning
+func FileContentType(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	head := make([]byte, SNIFF_LENGTH)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	return ContentType(head[:n]), nil
+}
@@ -0,0 +1,28 @@
+package sniff
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, "image/png", ContentType([]byte("\x89PNG\r\n\x1a\n")))
+	assert.Equal(t, "text/plain; charset=utf-8", ContentType([]byte(strings.Repeat("a", SNIFF_LENGTH)+"\x00")))
+}
+
+func TestFileContentType(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data")
+	assert.Nil(t, os.WriteFile(path, []byte("%PDF-1.4"), 0o644))
+
+	contentType, err := FileContentType(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "application/pdf", contentType)
+
+	_, err = FileContentType(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,35 @@
+package urls
+
+import (
+	"strings"
+)
+
+const UNIX_SOCKET_PREFIX = "unix://"
+
+// UNIX_SOCKET_HOST is the host used in http requests sent over a unix socket
+const UNIX_SOCKET_HOST = "localhost"
+
+// SplitUnixSocketUrl splits url in form of unix:///path/to/socket:/request/path into
+// socket path and request path. Request path defaults to / when missing.
+func SplitUnixSocketUrl(rawUrl string) (string, string, bool) {
+	if !strings.HasPrefix(rawUrl, UNIX_SOCKET_PREFIX) {
+		return "", "", false
+	}
+	rest := strings.TrimPrefix(rawUrl, UNIX_SOCKET_PREFIX)
+	socketPath, requestPath, found := strings.Cut(rest, ":")
+	if len(socketPath) == 0 {
+		return "", "", false
+	}
+	if !found || len(requestPath) == 0 {
+		requestPath = "/"
+	}
+	if !strings.HasPrefix(requestPath, "/") {
+		requestPath = "/" + requestPath
+	}
+	return socketPath, requestPath, true
+}
+
+// ToHttpUrl converts unix socket url to a http url sent over the socket.
+func ToHttpUrl(requestPath string) string {
+	return "http://" + UNIX_SOCKET_HOST + requestPath
+}
@@ -0,0 +1,32 @@
+package urls
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitUnixSocketUrl(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		expectedOk   bool
+		expectedSock string
+		expectedPath string
+	}{
+		{"With path", "unix:///var/run/docker.sock:/v1.43/containers/json?all=1", true, "/var/run/docker.sock", "/v1.43/containers/json?all=1"},
+		{"Without path", "unix:///var/run/docker.sock", true, "/var/run/docker.sock", "/"},
+		{"Path without slash", "unix:///tmp/app.sock:health", true, "/tmp/app.sock", "/health"},
+		{"Relative socket", "unix://app.sock:/health", true, "app.sock", "/health"},
+		{"Missing socket", "unix://:/health", false, "", ""},
+		{"Http url", "http://localhost:8000/health", false, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			socket, path, ok := SplitUnixSocketUrl(tt.url)
+			assert.Equal(t, tt.expectedOk, ok)
+			assert.Equal(t, tt.expectedSock, socket)
+			assert.Equal(t, tt.expectedPath, path)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package writer
 
 import (
 	"errors"
+	"io"
 	"os"
 
 	"github.com/rs/zerolog/log"
@@ -31,6 +32,37 @@ func WriteFile(path, contents string) (string, error) {
 	return file.Name(), nil
 }
 
+// CopyFile copies contents of srcPath to a new file at path
+func CopyFile(srcPath, path string) (string, error) {
+	if len(path) <= 0 {
+		return "", errors.New("path must not be empty.")
+	}
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(file, src)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to copy file")
+		file.Close()
+		return "", err
+	}
+	err = file.Close()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to close the file")
+		return "", err
+	}
+	return file.Name(), nil
+}
+
 func RenameFile(oldPath, newPath string) error {
 	if len(oldPath) <= 0 {
 		return errors.New("old path must not be empty.")
@@ -125,6 +125,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 		case "?":
+			if m.active == Requests && m.requests.CapturesInput() {
+				break
+			}
 			m.help.ShowAll = !m.help.ShowAll
 			return m, nil
 		}
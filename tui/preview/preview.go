@@ -6,8 +6,10 @@ import (
 	"strings"
 
 	"github.com/susiteemu/startpoint/core/ansi"
+	searchui "github.com/susiteemu/startpoint/tui/search"
 	"github.com/susiteemu/startpoint/tui/styles"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -22,6 +24,34 @@ type Model struct {
 	Viewport viewport.Model
 	wPercent float64
 	hPercent float64
+	search   searchui.Model
+	// lineOffsets holds the first wrapped line of each line of the content
+	lineOffsets []int
+}
+
+var closeKey = key.NewBinding(
+	key.WithKeys(tea.KeyEsc.String()),
+	key.WithHelp(tea.KeyEsc.String(), "close preview"),
+)
+
+func (m Model) ShortHelp() []key.Binding {
+	k := m.search.KeyMap
+	if m.search.Active() {
+		return []key.Binding{k.Next, k.Prev, k.Search, k.Regex, k.IgnoreCase, k.Clear}
+	}
+	return []key.Binding{k.Search, closeKey}
+}
+
+func (m Model) FullHelp() [][]key.Binding {
+	k := m.search.KeyMap
+	if m.search.Active() {
+		return [][]key.Binding{
+			{k.Search, k.Next, k.Prev},
+			{k.Regex, k.IgnoreCase},
+			{k.Clear},
+		}
+	}
+	return [][]key.Binding{{k.Search, closeKey}}
 }
 
 func (m Model) Init() tea.Cmd {
@@ -39,7 +69,45 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.Viewport.Width = int(float64(msg.Width) * m.wPercent)
 		m.Viewport.Height = int(float64(msg.Height) * m.hPercent)
-		m.Viewport.SetContent(renderLines(m.content, m.Viewport.Width-RENDER_LINE_MARGIN))
+		m.refreshContent()
+
+	case tea.KeyMsg:
+		if m.search.Focused() {
+			m.search, cmd = m.search.Update(msg)
+			m.refreshContent()
+			m.gotoMatch()
+			return m, cmd
+		}
+		switch msg.String() {
+		case "/":
+			cmd = m.search.Focus()
+			m.refreshContent()
+			return m, cmd
+		case "n", "N":
+			if m.search.Active() {
+				if msg.String() == "n" {
+					m.search.Next()
+				} else {
+					m.search.Prev()
+				}
+				m.refreshContent()
+				m.gotoMatch()
+			}
+			return m, nil
+		case tea.KeyEsc.String():
+			if m.search.Active() {
+				m.search.Clear()
+				m.refreshContent()
+			}
+			return m, nil
+		}
+
+	default:
+		if m.search.Focused() {
+			// e.g. blinking of the cursor
+			m.search, cmd = m.search.Update(msg)
+			cmds = append(cmds, cmd)
+		}
 	}
 
 	// Handle keyboard and mouse events in the viewport
@@ -50,7 +118,38 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 }
 
 func (m Model) View() string {
-	return lipgloss.NewStyle().BorderForeground(styles.LoadTheme().BorderFgColor).Border(lipgloss.RoundedBorder(), true, true).Render(contentStyle.Render(m.Viewport.View()))
+	content := m.Viewport.View()
+	if m.search.Active() {
+		// search line takes the last line of the viewport
+		v := m.Viewport
+		v.Height = max(v.Height-1, 1)
+		content = lipgloss.JoinVertical(lipgloss.Left, v.View(), m.search.View(m.Viewport.Width-RENDER_LINE_MARGIN))
+	}
+	return lipgloss.NewStyle().BorderForeground(styles.LoadTheme().BorderFgColor).Border(lipgloss.RoundedBorder(), true, true).Render(contentStyle.Render(content))
+}
+
+// Searching tells if there is a search. It handles esc itself.
+func (m Model) Searching() bool {
+	return m.search.Active()
+}
+
+// CapturesInput tells if the search gets all key input
+func (m Model) CapturesInput() bool {
+	return m.search.Focused()
+}
+
+// refreshContent renders the content with the matches of the search to the viewport
+func (m *Model) refreshContent() {
+	var rendered string
+	rendered, m.lineOffsets = renderLines(m.search.Highlight(m.content), m.Viewport.Width-RENDER_LINE_MARGIN)
+	m.Viewport.SetContent(rendered)
+}
+
+// gotoMatch scrolls to the current line with matches
+func (m *Model) gotoMatch() {
+	if line := m.search.Line(); line >= 0 && line < len(m.lineOffsets) {
+		m.Viewport.SetYOffset(m.lineOffsets[line])
+	}
 }
 
 func (m *Model) SetSize(width int, height int) {
@@ -58,7 +157,9 @@ func (m *Model) SetSize(width int, height int) {
 	m.Viewport.Height = height
 }
 
-func renderLines(content string, width int) string {
+// renderLines numbers and wraps the lines of content to width. Returns the wrapped content and
+// the index of the first wrapped line of each line.
+func renderLines(content string, width int) (string, []int) {
 	theme := styles.LoadTheme()
 
 	content = strings.ReplaceAll(content, "\r\n", "\n")
@@ -66,6 +167,8 @@ func renderLines(content string, width int) string {
 	digits := len(strconv.Itoa(len(lines)))
 	lineNrFmt := "%" + fmt.Sprintf("%d", digits) + "d"
 	linesWithLineNrs := []string{}
+	offsets := make([]int, 0, len(lines))
+	offset := 0
 	for i := 0; i < len(lines); i++ {
 		line := lines[i]
 		lineNr := i + 1
@@ -78,9 +181,11 @@ func renderLines(content string, width int) string {
 		}
 
 		linesWithLineNrs = append(linesWithLineNrs, line)
+		offsets = append(offsets, offset)
+		offset += strings.Count(line, "\n") + 1
 	}
 
-	return strings.Join(linesWithLineNrs, "\n")
+	return strings.Join(linesWithLineNrs, "\n"), offsets
 }
 
 func New(title, content string, w, h int, wPercent, hPercent float64) Model {
@@ -88,7 +193,6 @@ func New(title, content string, w, h int, wPercent, hPercent float64) Model {
 	width := int(float64(w) * wPercent)
 	height := int(float64(h) * hPercent)
 	v := viewport.New(width, height)
-	v.SetContent(renderLines(content, width-RENDER_LINE_MARGIN))
 	v.Style = v.Style.Padding(0, 0)
 
 	m := Model{
@@ -97,6 +201,8 @@ func New(title, content string, w, h int, wPercent, hPercent float64) Model {
 		Viewport: v,
 		wPercent: wPercent,
 		hPercent: hPercent,
+		search:   searchui.New(),
 	}
+	m.refreshContent()
 	return m
 }
@@ -10,29 +10,49 @@ import (
 	"time"
 
 	requestchain "github.com/susiteemu/startpoint/core/chaining"
+	"github.com/susiteemu/startpoint/core/client"
 	"github.com/susiteemu/startpoint/core/client/runner"
 	"github.com/susiteemu/startpoint/core/configuration"
 	"github.com/susiteemu/startpoint/core/editor"
 	"github.com/susiteemu/startpoint/core/loader"
 	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/session"
 	"github.com/susiteemu/startpoint/core/writer"
 
 	"github.com/susiteemu/startpoint/core/print"
+	resultsui "github.com/susiteemu/startpoint/tui/resultsview"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 )
 
-func doRequest(r *model.RequestMold, all []*model.RequestMold, profile *model.Profile) tea.Cmd {
+func doRequest(r *model.RequestMold, all []*model.RequestMold, profile *model.Profile, events chan RunRequestEventMsg) tea.Cmd {
 	// TODO: handle errors
 	return func() tea.Msg {
+		defer close(events)
 
 		chainedRequests := requestchain.ResolveRequestChain(r, all)
 
 		log.Debug().Msgf("Resolved %d chained requests", len(chainedRequests))
 
-		responses, err := runner.RunRequestChain(chainedRequests, profile, interimResult)
+		pretty := configuration.New().GetBoolWithDefault("printer.pretty", true)
+		responses, err := runner.RunRequestChainWithEvents(chainedRequests, profile, session.New(), interimResult, func(requestName string, event model.Event) {
+			printed, prettyPrinted, err := print.SprintEvent(event, pretty)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to print event of %s", requestName)
+				return
+			}
+			if !pretty {
+				prettyPrinted = printed
+			}
+			events <- RunRequestEventMsg{
+				RequestName: r.Name,
+				Results:     prettyPrinted,
+				RawResults:  printed,
+				events:      events,
+			}
+		})
 		if err != nil {
 			return RunRequestFinishedWithFailureMsg{
 				RequestName: r.Name,
@@ -42,6 +62,7 @@ func doRequest(r *model.RequestMold, all []*model.RequestMold, profile *model.Pr
 		responseCount := len(responses)
 		var printedResponses []string
 		var rawResponses []string
+		var bodies []resultsui.Body
 		for _, resp := range responses {
 			var config *configuration.Configuration = configuration.NewWithRequestOptions(resp.Options)
 			printResponse := config.GetBoolWithDefault("print", true)
@@ -55,7 +76,17 @@ func doRequest(r *model.RequestMold, all []*model.RequestMold, profile *model.Pr
 				PrintHeaders:   true,
 				PrintTraceInfo: config.GetBool("httpClient.enableTraceInfo"),
 				PrintRequest:   config.GetBoolWithDefault("printRequest", false),
+				// the whole body of a large response can be opened from the results view
+				PreviewBody: true,
 			}
+			headers := model.Headers(resp.Headers)
+			contentType, _ := headers.ContentType()
+			bodies = append(bodies, resultsui.Body{
+				RequestName: resp.RequestName,
+				ContentType: contentType,
+				Data:        resp.Body,
+				File:        resp.BodyFile,
+			})
 			log.Debug().Msgf("Printing with opts %v", printOpts)
 			printed, prettyPrinted, err := print.SprintResponse(resp, printOpts)
 			if err != nil {
@@ -96,11 +127,49 @@ func doRequest(r *model.RequestMold, all []*model.RequestMold, profile *model.Pr
 			RequestName: r.Name,
 			Results:     strings.Join(printedResponses, "\n"),
 			RawResults:  strings.Join(rawResponses, "\n"),
+			Bodies:      bodies,
 		}
 	}
 
 }
 
+// connectWebSocket runs the requests chained before a websocket request and opens its connection
+func connectWebSocket(r *model.RequestMold, all []*model.RequestMold, profile *model.Profile) tea.Cmd {
+	return func() tea.Msg {
+		chainedRequests := requestchain.ResolveRequestChain(r, all)
+		request, err := runner.PrepareRequest(chainedRequests, profile, session.New())
+		if err != nil {
+			return RunRequestFinishedWithFailureMsg{
+				RequestName: r.Name,
+				Results:     fmt.Sprintf("Error occurred: %v", err),
+			}
+		}
+		conn, err := client.DialWebSocket(request)
+		if err != nil {
+			return RunRequestFinishedWithFailureMsg{
+				RequestName: r.Name,
+				Results:     fmt.Sprintf("Error occurred: %v", err),
+			}
+		}
+		return WebSocketConnectedMsg{
+			RequestName: r.Name,
+			Request:     request,
+			Conn:        conn,
+		}
+	}
+}
+
+// waitForEvent returns the next streamed event of a running request or nil when the request has finished
+func waitForEvent(events <-chan RunRequestEventMsg) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return nil
+		}
+		return event
+	}
+}
+
 func interimResult(took time.Duration, statusCode int) {
 	log.Debug().Msgf("Request with statuscode %d took %s", statusCode, took.String())
 }
@@ -242,7 +311,11 @@ func copyRequest(name string, r Request, mold model.RequestMold) (Request, *mode
 }
 
 func changeMoldName(name string, m *model.RequestMold) {
-	m.Filename = fmt.Sprintf("%s.%s", name, m.Type)
+	if strings.HasSuffix(m.Filename, model.WEBSOCKET_YAML_EXT) {
+		m.Filename = name + model.WEBSOCKET_YAML_EXT
+	} else {
+		m.Filename = fmt.Sprintf("%s.%s", name, m.Type)
+	}
 	m.Name = name
 }
 
@@ -255,12 +328,27 @@ func readRequest(root, filename string) (Request, *model.RequestMold, bool) {
 	}
 	request := Request{
 		Name:   mold.Name,
-		Method: mold.Method(),
+		Method: displayMethod(mold),
 		Url:    mold.Url(),
 	}
 	return request, mold, true
 }
 
+// displayMethod returns method of the request shown in the list, WS for websocket, GRPC for
+// grpc and GQL for graphql requests
+func displayMethod(mold *model.RequestMold) string {
+	if mold.IsWebSocket() {
+		return "WS"
+	}
+	if mold.IsGrpc() {
+		return "GRPC"
+	}
+	if mold.IsGraphQL() {
+		return "GQL"
+	}
+	return mold.Method()
+}
+
 func RefreshProfiles(loadedProfiles []*model.Profile) {
 	envVars := os.Environ()
 	allProfiles = []*model.Profile{}
@@ -9,6 +9,7 @@ const (
 	Stopwatch
 	Profiles
 	Results
+	WebSocket
 )
 
 const (
@@ -16,6 +17,9 @@ const (
 	Edit
 )
 
+// MaxRunResults is how many latest results are kept of each request
+const MaxRunResults = 20
+
 const (
 	CreateRequestLabel = "Choose a name for your request. Make it filename compatible and unique within this workspace. After choosing \"ok\" your $EDITOR will open and you will be able to write the contents of the request. Remember to quit your editor window to return back."
 	RenameRequestLabel = "Rename your request."
@@ -1,7 +1,10 @@
 package requestui
 
 import (
+	"github.com/susiteemu/startpoint/core/client"
+	"github.com/susiteemu/startpoint/core/model"
 	keyprompt "github.com/susiteemu/startpoint/tui/keyprompt"
+	resultsui "github.com/susiteemu/startpoint/tui/resultsview"
 )
 
 type RunRequestMsg struct {
@@ -43,7 +46,23 @@ type RunRequestFinishedMsg struct {
 	RequestName string
 	Results     string
 	RawResults  string
+	Bodies      []resultsui.Body
 }
+
+// RunRequestEventMsg carries a server-sent event of a running request
+type RunRequestEventMsg struct {
+	RequestName string
+	Results     string
+	RawResults  string
+	events      <-chan RunRequestEventMsg
+}
+
+type WebSocketConnectedMsg struct {
+	RequestName string
+	Request     model.Request
+	Conn        *client.WebSocketConn
+}
+
 type RunRequestFinishedWithFailureMsg struct {
 	RequestName string
 	Results     string
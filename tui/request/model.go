@@ -6,6 +6,7 @@ import (
 	"github.com/susiteemu/startpoint/core/model"
 	"github.com/susiteemu/startpoint/core/print"
 	"github.com/susiteemu/startpoint/core/templating/templateng"
+	"github.com/susiteemu/startpoint/core/tools/urls"
 
 	keyprompt "github.com/susiteemu/startpoint/tui/keyprompt"
 	preview "github.com/susiteemu/startpoint/tui/preview"
@@ -13,6 +14,7 @@ import (
 	prompt "github.com/susiteemu/startpoint/tui/prompt"
 	resultsui "github.com/susiteemu/startpoint/tui/resultsview"
 	statusbar "github.com/susiteemu/startpoint/tui/statusbar"
+	websocketui "github.com/susiteemu/startpoint/tui/websocketview"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/list"
@@ -49,6 +51,7 @@ func (i Request) Description() string {
 		url = "<url>"
 	}
 	urlStyle = urlStyle.Foreground(style.urlFg).Background(style.urlBg)
+	templatedFg, templatedBg := style.urlTemplatedSectionFg, style.urlTemplatedSectionBg
 	if activeProfile != nil && processTemplateVariables {
 		for k, v := range activeProfile.Variables {
 			processedUrl, match := templateng.ProcessTemplateVariable(url, k, v)
@@ -56,17 +59,22 @@ func (i Request) Description() string {
 				url = processedUrl
 			}
 		}
-		url = print.HighlightWithRegex(url, `{[^{}]*}`, style.urlFg, style.urlBg, style.urlUnfilledTemplatedSectionFg, style.urlUnfilledTemplatedSectionBg)
-	} else {
-		url = print.HighlightWithRegex(url, `{[^{}]*}`, style.urlFg, style.urlBg, style.urlTemplatedSectionFg, style.urlTemplatedSectionBg)
+		templatedFg, templatedBg = style.urlUnfilledTemplatedSectionFg, style.urlUnfilledTemplatedSectionBg
 	}
+	socket := ""
+	if socketPath, requestPath, ok := urls.SplitUnixSocketUrl(url); ok {
+		// show socket apart from the path so that the path stays readable
+		socket = urlStyle.Faint(true).Render(fmt.Sprintf("[%s]", socketPath)) + " "
+		url = requestPath
+	}
+	url = print.HighlightWithRegex(url, `{[^{}]*}`, style.urlFg, style.urlBg, templatedFg, templatedBg)
 
 	method := i.Method
 	if i.Method == "" {
 		method = "<method>"
 	}
 
-	return lipgloss.JoinHorizontal(0, methodStyle.Render(method), " ", url)
+	return lipgloss.JoinHorizontal(0, methodStyle.Render(method), " ", socket, url)
 }
 func (i Request) FilterValue() string { return fmt.Sprintf("%s %s %s", i.Name, i.Method, i.Url) }
 
@@ -76,6 +84,7 @@ type Model struct {
 	list         list.Model
 	preview      preview.Model
 	resultview   resultsui.Model
+	wsview       websocketui.Model
 	prompt       prompt.Model
 	keyprompt    keyprompt.Model
 	stopwatch    stopwatch.Model
@@ -87,4 +96,6 @@ type Model struct {
 	postAction   PostAction
 	requestMolds []*model.RequestMold
 	runResults   map[string][]resultsui.RunResult
+	// name of the request whose streamed events are shown as its latest result
+	streaming string
 }
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/susiteemu/startpoint/core/configuration"
 	"github.com/susiteemu/startpoint/core/model"
 	"github.com/susiteemu/startpoint/core/print"
 
@@ -20,6 +21,7 @@ import (
 	resultsui "github.com/susiteemu/startpoint/tui/resultsview"
 	statusbar "github.com/susiteemu/startpoint/tui/statusbar"
 	"github.com/susiteemu/startpoint/tui/styles"
+	websocketui "github.com/susiteemu/startpoint/tui/websocketview"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
@@ -154,6 +156,14 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 		switch keypress := msg.String(); keypress {
 		case tea.KeyEsc.String():
+			if m.active == Results && (m.resultview.Exploring() || m.resultview.Searching()) {
+				// explorer and search close themselves
+				break
+			}
+			if m.active == Preview && m.preview.Searching() {
+				// search closes itself
+				break
+			}
 			if m.active == Preview || m.active == Prompt || m.active == Profiles || m.active == Keyprompt || m.active == Results {
 				m.active = List
 				return m, nil
@@ -207,10 +217,55 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			log.Error().Msgf("Could not find request mold with request %v", request)
 			return m, messages.CreateStatusMsg(fmt.Sprintf("Failed to run request %s", request.Title()))
 		}
+		if requestMold.IsWebSocket() {
+			return m, tea.Batch(
+				m.stopwatch.Init(),
+				connectWebSocket(requestMold, m.requestMolds, activeProfile),
+			)
+		}
+		events := make(chan RunRequestEventMsg)
+		m.streaming = ""
 		return m, tea.Batch(
 			m.stopwatch.Init(),
-			doRequest(requestMold, m.requestMolds, activeProfile),
+			doRequest(requestMold, m.requestMolds, activeProfile, events),
+			waitForEvent(events),
 		)
+	case RunRequestEventMsg:
+		runResults := m.runResults
+		if runResults == nil {
+			runResults = map[string][]resultsui.RunResult{}
+		}
+		r := runResults[msg.RequestName]
+		if m.streaming == msg.RequestName && len(r) > 0 {
+			latest := r[len(r)-1]
+			latest.Results += "\n" + msg.Results
+			latest.PlainResults += "\n" + msg.RawResults
+			r[len(r)-1] = latest
+			m.resultview.SetResults(r)
+		} else {
+			// first event: show results while the stream is still running
+			m.streaming = msg.RequestName
+			r = keepLatestResults(append(r, resultsui.RunResult{RequestName: msg.RequestName, RunAt: time.Now(), Results: msg.Results, PlainResults: msg.RawResults}))
+			m.resultview = resultsui.New(r, len(r)-1, m.width, m.height, 0.8, 0.8)
+			m.active = Results
+		}
+		runResults[msg.RequestName] = r
+		m.runResults = runResults
+		return m, waitForEvent(msg.events)
+	case WebSocketConnectedMsg:
+		pretty := configuration.NewWithRequestOptions(msg.Request.Options).GetBoolWithDefault("printer.pretty", true)
+		m.wsview = websocketui.New(msg.RequestName, msg.Request, msg.Conn, pretty, m.width, m.height, 0.8, 0.8)
+		m.active = WebSocket
+		return m, m.wsview.Init()
+	case websocketui.WebSocketClosedMsg:
+		// log of the connection is kept like results of other requests
+		return m, func() tea.Msg {
+			return RunRequestFinishedMsg{
+				RequestName: msg.RequestName,
+				Results:     msg.Results,
+				RawResults:  msg.RawResults,
+			}
+		}
 	case RunRequestFinishedMsg:
 		m.active = Results
 		runResults := m.runResults
@@ -222,7 +277,16 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			runResults[msg.RequestName] = []resultsui.RunResult{}
 		}
 		r := runResults[msg.RequestName]
-		r = append(r, resultsui.RunResult{RequestName: msg.RequestName, RunAt: time.Now(), Results: msg.Results, PlainResults: msg.RawResults})
+		result := resultsui.RunResult{RequestName: msg.RequestName, RunAt: time.Now(), Results: msg.Results, PlainResults: msg.RawResults, Bodies: msg.Bodies}
+		if m.streaming == msg.RequestName && len(r) > 0 {
+			// full response replaces the events streamed so far
+			result.RunAt = r[len(r)-1].RunAt
+			r[len(r)-1].RemoveBodyFiles()
+			r[len(r)-1] = result
+		} else {
+			r = keepLatestResults(append(r, result))
+		}
+		m.streaming = ""
 		runResults[msg.RequestName] = r
 		m.runResults = runResults
 		m.resultview = resultsui.New(r, len(r)-1, m.width, m.height, 0.8, 0.8)
@@ -240,7 +304,16 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			runResults[msg.RequestName] = []resultsui.RunResult{}
 		}
 		r := runResults[msg.RequestName]
-		r = append(r, resultsui.RunResult{RunAt: time.Now(), Results: msg.Results})
+		result := resultsui.RunResult{RunAt: time.Now(), Results: msg.Results}
+		if m.streaming == msg.RequestName && len(r) > 0 {
+			result.RunAt = r[len(r)-1].RunAt
+			result.Results = r[len(r)-1].Results + "\n\n" + msg.Results
+			r[len(r)-1].RemoveBodyFiles()
+			r[len(r)-1] = result
+		} else {
+			r = keepLatestResults(append(r, result))
+		}
+		m.streaming = ""
 		m.runResults = runResults
 		m.resultview = resultsui.New(r, len(r)-1, m.width, m.height, 0.8, 0.8)
 
@@ -569,6 +642,9 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case Results:
 		m.resultview, cmd = m.resultview.Update(msg)
 		cmds = append(cmds, cmd)
+	case WebSocket:
+		m.wsview, cmd = m.wsview.Update(msg)
+		cmds = append(cmds, cmd)
 	}
 	return m, tea.Batch(cmds...)
 }
@@ -589,6 +665,8 @@ func (m Model) View() string {
 		return renderProfiles(m)
 	case Results:
 		return renderResults(m)
+	case WebSocket:
+		return renderWebSocket(m)
 	default:
 		return renderList(m)
 	}
@@ -608,12 +686,21 @@ func renderList(m Model) string {
 	return joined
 }
 
+// CapturesInput tells if a text input of the active view gets all key input
+func (m Model) CapturesInput() bool {
+	return (m.active == Results && m.resultview.Filtering()) || (m.active == Preview && m.preview.CapturesInput())
+}
+
 func (m *Model) GetHelpKeys() help.KeyMap {
 	switch m.active {
 	case List:
 		return m.list
+	case Preview:
+		return m.preview
 	case Results:
 		return m.resultview
+	case WebSocket:
+		return m.wsview
 	default:
 		return m.list
 	}
@@ -636,6 +723,12 @@ func renderStopwatch(m Model) string {
 	return renderModal(renderList(m), style.stopwatchStyle.Render("Running request\n\n"+m.stopwatch.View()), w, h)
 }
 
+func renderWebSocket(m Model) string {
+	w := m.width
+	h := m.height
+	return renderModal(renderList(m), m.wsview.View(), w, h)
+}
+
 func renderPreview(m Model) string {
 	w := m.width
 	h := m.height
@@ -679,7 +772,7 @@ func New(loadedRequests []*model.RequestMold, loadedProfiles []*model.Profile) M
 		r := Request{
 			Name:   v.Name,
 			Url:    v.Url(),
-			Method: v.Method(),
+			Method: displayMethod(v),
 		}
 		requests = append(requests, r)
 	}
@@ -746,3 +839,16 @@ func New(loadedRequests []*model.RequestMold, loadedProfiles []*model.Profile) M
 	}
 
 }
+
+// keepLatestResults discards the oldest results of a request when there are more than
+// MaxRunResults of them
+func keepLatestResults(results []resultsui.RunResult) []resultsui.RunResult {
+	if len(results) <= MaxRunResults {
+		return results
+	}
+	discarded := len(results) - MaxRunResults
+	for _, result := range results[:discarded] {
+		result.RemoveBodyFiles()
+	}
+	return slices.Clone(results[discarded:])
+}
@@ -0,0 +1,275 @@
+package resultsui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/rs/zerolog/log"
+	"github.com/susiteemu/startpoint/core/jsontree"
+	messages "github.com/susiteemu/startpoint/tui/messages"
+	"github.com/susiteemu/startpoint/tui/styles"
+	"gopkg.in/yaml.v3"
+)
+
+// EXPLORER_HEADER_HEIGHT is the height of the breadcrumb and filter lines above the tree
+const EXPLORER_HEADER_HEIGHT = 2
+
+// explorer shows a json body as a collapsible tree which can be narrowed with a filter
+type explorer struct {
+	tree      *jsontree.Node
+	shown     *jsontree.Node
+	document  *yaml.Node
+	lines     []*jsontree.Node
+	cursor    int
+	offset    int
+	filter    textinput.Model
+	filterErr string
+	keyMap    explorerKeyMap
+}
+
+type explorerKeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	Toggle   key.Binding
+	Expand   key.Binding
+	Collapse key.Binding
+	All      key.Binding
+	CopyPath key.Binding
+	CopyVal  key.Binding
+	Filter   key.Binding
+	Close    key.Binding
+}
+
+var explorerKeys = explorerKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "down"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys("enter", " "),
+		key.WithHelp("enter", "toggle"),
+	),
+	Expand: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→/l", "expand"),
+	),
+	Collapse: key.NewBinding(
+		key.WithKeys("left", "h"),
+		key.WithHelp("←/h", "collapse"),
+	),
+	All: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "expand/collapse all"),
+	),
+	CopyPath: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "copy path"),
+	),
+	CopyVal: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "copy value"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "filter (jq path/JSONPath)"),
+	),
+	Close: key.NewBinding(
+		key.WithKeys("t", tea.KeyEsc.String()),
+		key.WithHelp("t/esc", "close explorer"),
+	),
+}
+
+func newExplorer(body []byte, width int) (*explorer, error) {
+	tree, document, err := jsontree.Parse(body)
+	if err != nil {
+		return nil, err
+	}
+	filter := textinput.New()
+	filter.Prompt = "filter: "
+	filter.Placeholder = "jq path .items[].name or JSONPath $.items[*].name"
+	// leave room for the cursor
+	filter.Width = width - RENDER_LINE_MARGIN - len(filter.Prompt) - 1
+
+	e := &explorer{
+		tree:     tree,
+		shown:    tree,
+		document: document,
+		filter:   filter,
+		keyMap:   explorerKeys,
+	}
+	e.lines = e.shown.Visible()
+	return e, nil
+}
+
+func (e *explorer) filtering() bool {
+	return e.filter.Focused()
+}
+
+func (e *explorer) current() *jsontree.Node {
+	return e.lines[e.cursor]
+}
+
+// refresh recalculates visible lines keeping the cursor on the given node when it is visible
+func (e *explorer) refresh(at *jsontree.Node) {
+	e.lines = e.shown.Visible()
+	e.cursor = 0
+	for i, line := range e.lines {
+		if line == at {
+			e.cursor = i
+			break
+		}
+	}
+}
+
+func (e *explorer) applyFilter() {
+	expression := strings.TrimSpace(e.filter.Value())
+	if len(expression) == 0 {
+		e.filterErr = ""
+		e.shown = e.tree
+		e.refresh(nil)
+		return
+	}
+	filtered, err := jsontree.Filter(e.document, expression)
+	if err != nil {
+		// keep showing the previous result while the expression is being written
+		e.filterErr = err.Error()
+		return
+	}
+	e.filterErr = ""
+	e.shown = filtered
+	e.refresh(nil)
+}
+
+// update handles a key press. Returns false when the explorer should be closed.
+func (e *explorer) update(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if e.filtering() {
+		switch msg.String() {
+		case tea.KeyEnter.String():
+			e.filter.Blur()
+			return true, nil
+		case tea.KeyEsc.String():
+			e.filter.Blur()
+			e.filter.SetValue("")
+			e.applyFilter()
+			return true, nil
+		}
+		var cmd tea.Cmd
+		e.filter, cmd = e.filter.Update(msg)
+		e.applyFilter()
+		return true, cmd
+	}
+
+	switch {
+	case key.Matches(msg, e.keyMap.Close):
+		return false, nil
+	case key.Matches(msg, e.keyMap.Up):
+		e.cursor = max(e.cursor-1, 0)
+	case key.Matches(msg, e.keyMap.Down):
+		e.cursor = min(e.cursor+1, len(e.lines)-1)
+	case key.Matches(msg, e.keyMap.Toggle):
+		node := e.current()
+		node.Expanded = !node.Expanded
+		e.refresh(node)
+	case key.Matches(msg, e.keyMap.Expand):
+		node := e.current()
+		node.Expanded = true
+		e.refresh(node)
+	case key.Matches(msg, e.keyMap.Collapse):
+		node := e.current()
+		if node.Expanded && node.IsContainer() {
+			node.Expanded = false
+		} else if node.Parent != nil {
+			node = node.Parent
+			node.Expanded = false
+		}
+		e.refresh(node)
+	case key.Matches(msg, e.keyMap.All):
+		node := e.current()
+		node.SetExpanded(!node.Expanded)
+		e.refresh(node)
+	case key.Matches(msg, e.keyMap.CopyPath):
+		return true, copyToClipboard(e.current().Path, "path")
+	case key.Matches(msg, e.keyMap.CopyVal):
+		return true, copyToClipboard(e.current().Json(), "value")
+	case key.Matches(msg, e.keyMap.Filter):
+		return true, e.filter.Focus()
+	}
+	return true, nil
+}
+
+func copyToClipboard(s, what string) tea.Cmd {
+	err := clipboard.WriteAll(s)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to copy %s to clipboard", what)
+		return messages.CreateStatusMsg(fmt.Sprintf("Failed to copy %s to clipboard", what))
+	}
+	return messages.CreateStatusMsg(fmt.Sprintf("Copied %s to clipboard", what))
+}
+
+func (e *explorer) view(width, height int) string {
+	theme := styles.LoadTheme()
+	faint := lipgloss.NewStyle().Foreground(theme.TextFgColor).Faint(true)
+	keyStyle := lipgloss.NewStyle().Foreground(theme.ResponseHeaderFgColor)
+	cursorStyle := lipgloss.NewStyle().Foreground(theme.CursorFgColor).Background(theme.CursorBgColor)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.ErrorFgColor)
+
+	lineWidth := width - RENDER_LINE_MARGIN
+	var views []string
+	views = append(views, xansi.Truncate(faint.Render(e.current().Path), lineWidth, "…"))
+	filterLine := e.filter.View()
+	if e.filterErr != "" {
+		filterLine += " " + errorStyle.Render(e.filterErr)
+	}
+	views = append(views, xansi.Truncate(filterLine, lineWidth, "…"))
+
+	treeHeight := max(height-EXPLORER_HEADER_HEIGHT, 1)
+	if e.cursor < e.offset {
+		e.offset = e.cursor
+	} else if e.cursor >= e.offset+treeHeight {
+		e.offset = e.cursor - treeHeight + 1
+	}
+	e.offset = max(min(e.offset, len(e.lines)-treeHeight), 0)
+
+	for i := e.offset; i < min(e.offset+treeHeight, len(e.lines)); i++ {
+		node := e.lines[i]
+		marker := "  "
+		if node.IsContainer() && node.Expanded {
+			marker = "▾ "
+		} else if node.IsContainer() {
+			marker = "▸ "
+		}
+		label := node.Path + " "
+		if node.Parent != nil {
+			label = node.Key + ": "
+			if node.Parent.IsArray() {
+				label = "[" + node.Key + "] "
+			}
+		}
+		summary := node.Summary()
+		if node.IsContainer() && node.Expanded {
+			summary = ""
+		}
+		indent := strings.Repeat("  ", node.Depth)
+		var line string
+		if i == e.cursor {
+			line = cursorStyle.Render(xansi.Truncate(indent+marker+label+summary, lineWidth, "…"))
+		} else {
+			line = xansi.Truncate(indent+faint.Render(marker)+keyStyle.Render(label)+summary, lineWidth, "…")
+		}
+		views = append(views, line)
+	}
+	for len(views) < height {
+		views = append(views, "")
+	}
+	return strings.Join(views, "\n")
+}
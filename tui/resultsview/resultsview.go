@@ -2,6 +2,7 @@ package resultsui
 
 import (
 	"fmt"
+	"mime"
 	"path/filepath"
 	"strings"
 	"time"
@@ -15,8 +16,11 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 	"github.com/susiteemu/startpoint/core/ansi"
+	"github.com/susiteemu/startpoint/core/client"
+	"github.com/susiteemu/startpoint/core/editor"
 	"github.com/susiteemu/startpoint/core/writer"
 	messages "github.com/susiteemu/startpoint/tui/messages"
+	searchui "github.com/susiteemu/startpoint/tui/search"
 	"github.com/susiteemu/startpoint/tui/styles"
 )
 
@@ -30,6 +34,25 @@ type RunResult struct {
 	RunAt        time.Time
 	Results      string
 	PlainResults string
+	Bodies       []Body
+}
+
+// Body is a response body of a run which can be saved or opened outside of the results view
+type Body struct {
+	RequestName string
+	ContentType string
+	Data        []byte
+	// File holds the whole body when it was too large to be kept in memory
+	File string
+}
+
+// RemoveBodyFiles removes the files of large bodies when the result is discarded
+func (r RunResult) RemoveBodyFiles() {
+	for _, body := range r.Bodies {
+		if len(body.File) > 0 {
+			client.RemoveBodyFile(body.File)
+		}
+	}
 }
 
 type Model struct {
@@ -41,6 +64,10 @@ type Model struct {
 	wPercent  float64
 	hPercent  float64
 	keyMap    keyMap
+	explorer  *explorer
+	search    searchui.Model
+	// lineOffsets holds the first wrapped line of each line of the content
+	lineOffsets []int
 }
 
 type keyMap struct {
@@ -48,17 +75,45 @@ type keyMap struct {
 	Close     key.Binding
 	Copy      key.Binding
 	Export    key.Binding
+	Open      key.Binding
+	Save      key.Binding
+	Explore   key.Binding
 	CloseHelp key.Binding
 }
 
 func (m Model) ShortHelp() []key.Binding {
-	return []key.Binding{m.keyMap.Next, m.keyMap.Close, m.keyMap.Copy, m.keyMap.Export, m.keyMap.CloseHelp}
+	if m.explorer != nil {
+		k := m.explorer.keyMap
+		return []key.Binding{k.Toggle, k.CopyPath, k.CopyVal, k.Filter, k.Close, m.keyMap.CloseHelp}
+	}
+	if m.search.Active() {
+		k := m.search.KeyMap
+		return []key.Binding{k.Next, k.Prev, k.Search, k.Regex, k.IgnoreCase, k.Clear, m.keyMap.CloseHelp}
+	}
+	return []key.Binding{m.keyMap.Next, m.keyMap.Close, m.keyMap.Copy, m.keyMap.Export, m.keyMap.Open, m.keyMap.Save, m.keyMap.Explore, m.search.KeyMap.Search, m.keyMap.CloseHelp}
 }
 
 func (m Model) FullHelp() [][]key.Binding {
+	if m.explorer != nil {
+		k := m.explorer.keyMap
+		return [][]key.Binding{
+			{k.Up, k.Down, k.Toggle, k.Expand, k.Collapse},
+			{k.All, k.CopyPath, k.CopyVal, k.Filter},
+			{k.Close, m.keyMap.CloseHelp},
+		}
+	}
+	if m.search.Active() {
+		k := m.search.KeyMap
+		return [][]key.Binding{
+			{k.Search, k.Next, k.Prev},
+			{k.Regex, k.IgnoreCase},
+			{k.Clear, m.keyMap.CloseHelp},
+		}
+	}
 	return [][]key.Binding{
 		{m.keyMap.Next, m.keyMap.Copy, m.keyMap.Export},
-		{m.keyMap.Close, m.keyMap.CloseHelp},
+		{m.keyMap.Open, m.keyMap.Save, m.keyMap.Explore},
+		{m.search.KeyMap.Search, m.keyMap.Close, m.keyMap.CloseHelp},
 	}
 }
 
@@ -79,6 +134,18 @@ var embeddedKeys = keyMap{
 		key.WithKeys(tea.KeyEsc.String()),
 		key.WithHelp("w", "write to file"),
 	),
+	Open: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "open large body in $PAGER/$EDITOR"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "save body to file"),
+	),
+	Explore: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "explore json as a tree"),
+	),
 	CloseHelp: key.NewBinding(
 		key.WithKeys("?"),
 		key.WithHelp("?", "close help"),
@@ -100,22 +167,56 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.Viewport.Width = int(float64(msg.Width) * m.wPercent)
 		m.Viewport.Height = int(float64(msg.Height)*m.hPercent) - TOP_NAVIGATION_HEIGHT
-		content := getActiveContent(m)
-		m.Viewport.SetContent(renderLines(content, m.Viewport.Width-RENDER_LINE_MARGIN))
+		m.refreshContent()
 		m.width = msg.Width
 		m.height = msg.Height
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.explorer != nil {
+			open, cmd := m.explorer.update(msg)
+			if !open {
+				m.explorer = nil
+			}
+			return m, cmd
+		}
+		if m.search.Focused() {
+			m.search, cmd = m.search.Update(msg)
+			m.refreshContent()
+			m.gotoMatch()
+			return m, cmd
+		}
 
 		switch keypress := msg.String(); keypress {
+		case "/":
+			cmd = m.search.Focus()
+			m.refreshContent()
+			return m, cmd
 		case "n":
+			if m.search.Active() {
+				m.search.Next()
+				m.refreshContent()
+				m.gotoMatch()
+				return m, nil
+			}
 			m.activeIdx += 1
 			if m.activeIdx >= len(m.results) {
 				m.activeIdx = 0
 			}
-			content := getActiveContent(m)
-			m.Viewport.SetContent(renderLines(content, m.Viewport.Width-RENDER_LINE_MARGIN))
+			m.refreshContent()
+			return m, nil
+		case "N":
+			if m.search.Active() {
+				m.search.Prev()
+				m.refreshContent()
+				m.gotoMatch()
+			}
+			return m, nil
+		case tea.KeyEsc.String():
+			if m.search.Active() {
+				m.search.Clear()
+				m.refreshContent()
+			}
 			return m, nil
 		case "c":
 			rawResults := m.results[m.activeIdx].PlainResults
@@ -137,9 +238,77 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 				return m, messages.CreateStatusMsg("Failed to write results to file")
 			}
 			return m, messages.CreateStatusMsg(fmt.Sprintf("Wrote results to \"%s\"", path))
+		case "o":
+			var bodyFiles []string
+			for _, body := range m.results[m.activeIdx].Bodies {
+				if len(body.File) > 0 {
+					bodyFiles = append(bodyFiles, body.File)
+				}
+			}
+			if len(bodyFiles) == 0 {
+				return m, messages.CreateStatusMsg("Results are shown in full, there is no body to open")
+			}
+			cmd, err := editor.OpenFilesToPagerCmd(bodyFiles...)
+			if err != nil {
+				return m, messages.CreateStatusMsg("Failed preparing pager")
+			}
+			return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+				if err != nil {
+					log.Error().Err(err).Msgf("Failed to open %v", bodyFiles)
+					return messages.CreateStatusMsg("Failed to open body")()
+				}
+				return nil
+			})
+		case "s":
+			results := m.results[m.activeIdx]
+			if len(results.Bodies) == 0 {
+				return m, messages.CreateStatusMsg("There is no body to save")
+			}
+			// the last response is the one of the run request, others are of its chained requests
+			body := results.Bodies[len(results.Bodies)-1]
+			workspace := viper.GetString("workspace")
+			name := fmt.Sprintf("%s_%s%s", body.RequestName, results.RunAt.Format(time.ANSIC), bodyFileExtension(body.ContentType))
+			path := filepath.Join(workspace, name)
+			var err error
+			if len(body.File) > 0 {
+				_, err = writer.CopyFile(body.File, path)
+			} else {
+				_, err = writer.WriteFile(path, string(body.Data))
+			}
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to save body to file")
+				return m, messages.CreateStatusMsg("Failed to save body to file")
+			}
+			return m, messages.CreateStatusMsg(fmt.Sprintf("Saved body to \"%s\"", path))
+		case "t":
+			bodies := m.results[m.activeIdx].Bodies
+			if len(bodies) == 0 {
+				return m, messages.CreateStatusMsg("There is no body to explore")
+			}
+			body := bodies[len(bodies)-1]
+			if len(body.File) > 0 {
+				return m, messages.CreateStatusMsg("Body is too large to explore, open it with o instead")
+			}
+			explorer, err := newExplorer(body.Data, m.Viewport.Width)
+			if err != nil {
+				log.Debug().Err(err).Msg("Cannot explore body")
+				return m, messages.CreateStatusMsg("Body is not json")
+			}
+			m.explorer = explorer
+			return m, nil
 		}
 	}
 
+	if m.explorer != nil {
+		// e.g. blinking of the filter cursor
+		m.explorer.filter, cmd = m.explorer.filter.Update(msg)
+		return m, cmd
+	}
+	if m.search.Focused() {
+		m.search, cmd = m.search.Update(msg)
+		return m, cmd
+	}
+
 	// Handle keyboard and mouse events in the viewport
 	m.Viewport, cmd = m.Viewport.Update(msg)
 
@@ -154,8 +323,16 @@ func (m Model) View() string {
 	nextResult := lipgloss.NewStyle().Faint(m.activeIdx+1 >= len(m.results)).Render("❯")
 	activeRun := fmt.Sprintf("[%d/%d] %s", m.activeIdx+1, len(m.results), m.results[m.activeIdx].RunAt.Format(time.Stamp))
 
-	views = append(views, lipgloss.NewStyle().Width(m.Viewport.Width).Align(lipgloss.Center).Render(fmt.Sprintf("%s %s %s", prevResult, activeRun, nextResult)))
-	views = append(views, contentStyle.Render(m.Viewport.View()))
+	if m.search.Active() && m.explorer == nil {
+		views = append(views, contentStyle.Render(m.search.View(m.Viewport.Width-RENDER_LINE_MARGIN)))
+	} else {
+		views = append(views, lipgloss.NewStyle().Width(m.Viewport.Width).Align(lipgloss.Center).Render(fmt.Sprintf("%s %s %s", prevResult, activeRun, nextResult)))
+	}
+	if m.explorer != nil {
+		views = append(views, contentStyle.Render(m.explorer.view(m.Viewport.Width, m.Viewport.Height)))
+	} else {
+		views = append(views, contentStyle.Render(m.Viewport.View()))
+	}
 	joined := lipgloss.JoinVertical(
 		lipgloss.Top,
 		views...,
@@ -165,10 +342,14 @@ func (m Model) View() string {
 	return joined
 }
 
-func renderLines(content string, width int) string {
+// renderLines wraps the lines of content to width. Returns the wrapped content and the index of
+// the first wrapped line of each line.
+func renderLines(content string, width int) (string, []int) {
 	content = strings.ReplaceAll(content, "\r\n", "\n")
 	lines := strings.Split(content, "\n")
 	wrappedLines := []string{}
+	offsets := make([]int, 0, len(lines))
+	offset := 0
 	for i := 0; i < len(lines); i++ {
 		line := lines[i]
 		if lipgloss.Width(line) >= width {
@@ -177,25 +358,80 @@ func renderLines(content string, width int) string {
 			line = strings.ReplaceAll(line, "\n", "\u001b[0m\n"+colorState.State)
 		}
 		wrappedLines = append(wrappedLines, line)
+		offsets = append(offsets, offset)
+		offset += strings.Count(line, "\n") + 1
+	}
+
+	return strings.Join(wrappedLines, "\n"), offsets
+}
+
+// refreshContent renders the active result with the matches of the search to the viewport
+func (m *Model) refreshContent() {
+	content := m.search.Highlight(getActiveContent(*m))
+	var rendered string
+	rendered, m.lineOffsets = renderLines(content, m.Viewport.Width-RENDER_LINE_MARGIN)
+	m.Viewport.SetContent(rendered)
+}
+
+// gotoMatch scrolls to the current line with matches
+func (m *Model) gotoMatch() {
+	if line := m.search.Line(); line >= 0 && line < len(m.lineOffsets) {
+		m.Viewport.SetYOffset(m.lineOffsets[line])
 	}
+}
 
-	return strings.Join(wrappedLines, "\n")
+// bodyFileExtension returns a file extension matching the content type or .bin when there is none
+func bodyFileExtension(contentType string) string {
+	extensions, err := mime.ExtensionsByType(contentType)
+	if err != nil || len(extensions) == 0 {
+		return ".bin"
+	}
+	return extensions[0]
 }
 
 func getActiveContent(m Model) string {
 	return m.results[m.activeIdx].Results
 }
 
+// Exploring tells if the json explorer is open. It handles esc itself.
+func (m Model) Exploring() bool {
+	return m.explorer != nil
+}
+
+// Filtering tells if the filter of the json explorer or the search gets all key input
+func (m Model) Filtering() bool {
+	return (m.explorer != nil && m.explorer.filtering()) || m.search.Focused()
+}
+
+// Searching tells if there is a search. It handles esc itself.
+func (m Model) Searching() bool {
+	return m.search.Active()
+}
+
+// SetResults replaces results keeping the scroll position. A view scrolled to the bottom stays
+// at the bottom so that streamed results can be followed as they arrive.
+func (m *Model) SetResults(results []RunResult) {
+	if len(results) == 0 {
+		return
+	}
+	atBottom := m.Viewport.AtBottom()
+	m.results = results
+	if m.activeIdx >= len(results) {
+		m.activeIdx = len(results) - 1
+	}
+	m.refreshContent()
+	if atBottom {
+		m.Viewport.GotoBottom()
+	}
+}
+
 func New(results []RunResult, activeIdx, w, h int, wPercent, hPercent float64) Model {
 	theme := styles.LoadTheme()
 	commonStyles = styles.GetCommonStyles(theme)
-	content := results[activeIdx].Results
-
 	width := int(float64(w) * wPercent)
 	height := int(float64(h) * hPercent)
 
 	v := viewport.New(width, height-TOP_NAVIGATION_HEIGHT)
-	v.SetContent(renderLines(content, width-RENDER_LINE_MARGIN))
 	v.Style = v.Style.Padding(0, 0)
 
 	m := Model{
@@ -207,6 +443,8 @@ func New(results []RunResult, activeIdx, w, h int, wPercent, hPercent float64) M
 		width:     w,
 		height:    h,
 		keyMap:    embeddedKeys,
+		search:    searchui.New(),
 	}
+	m.refreshContent()
 	return m
 }
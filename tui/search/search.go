@@ -0,0 +1,251 @@
+package searchui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/susiteemu/startpoint/core/print"
+	"github.com/susiteemu/startpoint/tui/styles"
+)
+
+// Model searches text of a view. Lines with matches can be stepped through and the matches of
+// the current line are highlighted differently from the others.
+type Model struct {
+	input      textinput.Model
+	Regex      bool
+	IgnoreCase bool
+	pattern    *regexp.Regexp
+	err        string
+	// matches holds indexes of the lines with matches
+	matches    []int
+	matchCount int
+	current    int
+	KeyMap     KeyMap
+}
+
+type KeyMap struct {
+	Search     key.Binding
+	Next       key.Binding
+	Prev       key.Binding
+	Regex      key.Binding
+	IgnoreCase key.Binding
+	Clear      key.Binding
+}
+
+var Keys = KeyMap{
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search"),
+	),
+	Next: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "next match"),
+	),
+	Prev: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "previous match"),
+	),
+	Regex: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "toggle regex while typing"),
+	),
+	IgnoreCase: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("ctrl+t", "toggle ignore case while typing"),
+	),
+	Clear: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String()),
+		key.WithHelp(tea.KeyEsc.String(), "clear search"),
+	),
+}
+
+func New() Model {
+	input := textinput.New()
+	input.Prompt = "/"
+	input.Placeholder = "search"
+	return Model{
+		input:  input,
+		KeyMap: Keys,
+	}
+}
+
+// Focus starts writing a new search
+func (m *Model) Focus() tea.Cmd {
+	m.input.SetValue("")
+	m.compile()
+	return m.input.Focus()
+}
+
+// Focused tells if the search input gets all key input
+func (m Model) Focused() bool {
+	return m.input.Focused()
+}
+
+// Active tells if there is a search whose matches are highlighted
+func (m Model) Active() bool {
+	return m.Focused() || m.pattern != nil
+}
+
+// Clear stops searching
+func (m *Model) Clear() {
+	m.input.Blur()
+	m.input.SetValue("")
+	m.compile()
+}
+
+// Update handles input while the search is being written. Enter keeps the search and esc
+// cancels it.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if !m.Focused() {
+		return m, nil
+	}
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		// e.g. blinking of the cursor
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	switch {
+	case keyMsg.String() == tea.KeyEnter.String():
+		m.input.Blur()
+		if m.pattern == nil {
+			m.Clear()
+		}
+		return m, nil
+	case keyMsg.String() == tea.KeyEsc.String():
+		m.Clear()
+		return m, nil
+	case key.Matches(keyMsg, m.KeyMap.Regex):
+		m.Regex = !m.Regex
+		m.compile()
+		return m, nil
+	case key.Matches(keyMsg, m.KeyMap.IgnoreCase):
+		m.IgnoreCase = !m.IgnoreCase
+		m.compile()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(keyMsg)
+	m.compile()
+	return m, cmd
+}
+
+func (m *Model) compile() {
+	m.pattern = nil
+	m.err = ""
+	m.current = 0
+	value := m.input.Value()
+	if len(value) == 0 {
+		return
+	}
+	if !m.Regex {
+		value = regexp.QuoteMeta(value)
+	}
+	if m.IgnoreCase {
+		value = "(?i)" + value
+	}
+	pattern, err := regexp.Compile(value)
+	if err != nil {
+		// keep typing until the expression is complete
+		m.err = err.Error()
+		return
+	}
+	m.pattern = pattern
+}
+
+// Highlight highlights the matches of content, keeping its colors, and remembers the lines
+// with matches
+func (m *Model) Highlight(content string) string {
+	m.matches = nil
+	m.matchCount = 0
+	if m.pattern == nil {
+		return content
+	}
+	theme := styles.LoadTheme()
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		plainLine := xansi.Strip(line)
+		if !m.pattern.MatchString(plainLine) {
+			continue
+		}
+		fg, bg := theme.TitleFgColor, theme.TitleBgColor
+		if len(m.matches) == m.current {
+			fg, bg = theme.CursorFgColor, theme.CursorBgColor
+		}
+		highlighted, count := print.HighlightWithRegexKeepingAnsi(line, m.pattern, fg, bg)
+		if count == 0 {
+			continue
+		}
+		lines[i] = highlighted
+		m.matches = append(m.matches, i)
+		m.matchCount += count
+	}
+	if m.current >= len(m.matches) {
+		m.current = 0
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Line returns the index of the current line with matches or -1 when there are none
+func (m Model) Line() int {
+	if len(m.matches) == 0 {
+		return -1
+	}
+	return m.matches[m.current]
+}
+
+// Next moves to the next line with matches. Content has to be highlighted again afterwards.
+func (m *Model) Next() {
+	if len(m.matches) > 0 {
+		m.current = (m.current + 1) % len(m.matches)
+	}
+}
+
+// Prev moves to the previous line with matches. Content has to be highlighted again afterwards.
+func (m *Model) Prev() {
+	if len(m.matches) > 0 {
+		m.current = (m.current - 1 + len(m.matches)) % len(m.matches)
+	}
+}
+
+// View renders the search input with its modes and matches in a single line
+func (m Model) View(width int) string {
+	theme := styles.LoadTheme()
+	faint := lipgloss.NewStyle().Foreground(theme.TextFgColor).Faint(true)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.ErrorFgColor)
+
+	var modes []string
+	if m.Regex {
+		modes = append(modes, "regex")
+	}
+	if m.IgnoreCase {
+		modes = append(modes, "ignore case")
+	}
+	var status string
+	switch {
+	case len(m.err) > 0:
+		status = errorStyle.Render(m.err)
+	case m.pattern == nil:
+		status = ""
+	case len(m.matches) == 0:
+		status = errorStyle.Render("no matches")
+	default:
+		status = faint.Render(fmt.Sprintf("[%d/%d] %d matches", m.current+1, len(m.matches), m.matchCount))
+	}
+	if len(modes) > 0 {
+		status = faint.Render("("+strings.Join(modes, ", ")+")") + " " + status
+	}
+
+	input := m.input.View()
+	if !m.Focused() {
+		input = "/" + m.input.Value()
+	}
+	return xansi.Truncate(input+" "+status, width, "…")
+}
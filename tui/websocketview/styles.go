@@ -0,0 +1,10 @@
+package websocketui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	contentStyle = lipgloss.NewStyle().MarginLeft(1).MarginRight(1)
+	inputStyle   = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderTop(true).MarginLeft(1).MarginRight(1)
+)
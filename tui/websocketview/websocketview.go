@@ -0,0 +1,273 @@
+package websocketui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/wrap"
+	"github.com/rs/zerolog/log"
+	"github.com/susiteemu/startpoint/core/client"
+	"github.com/susiteemu/startpoint/core/model"
+	"github.com/susiteemu/startpoint/core/print"
+	"github.com/susiteemu/startpoint/tui/styles"
+)
+
+const TOP_NAVIGATION_HEIGHT = 1
+const INPUT_HEIGHT = 2
+const RENDER_LINE_MARGIN = 4
+
+// WebSocketClosedMsg is sent when the user closes the view. It carries the log of the connection.
+type WebSocketClosedMsg struct {
+	RequestName string
+	RunAt       time.Time
+	Results     string
+	RawResults  string
+}
+
+type frameMsg struct {
+	frame model.WebSocketFrame
+}
+
+type disconnectedMsg struct {
+	err error
+}
+
+type sendFailedMsg struct {
+	err error
+}
+
+type Model struct {
+	requestName string
+	url         string
+	conn        *client.WebSocketConn
+	scripted    []string
+	runAt       time.Time
+	pretty      bool
+	lines       []string
+	plainLines  []string
+	connected   bool
+	Viewport    viewport.Model
+	input       textinput.Model
+	wPercent    float64
+	hPercent    float64
+	keyMap      keyMap
+}
+
+type keyMap struct {
+	Send  key.Binding
+	Close key.Binding
+}
+
+func (m Model) ShortHelp() []key.Binding {
+	return []key.Binding{m.keyMap.Send, m.keyMap.Close}
+}
+
+func (m Model) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{m.keyMap.Send, m.keyMap.Close},
+	}
+}
+
+var keys = keyMap{
+	Send: key.NewBinding(
+		key.WithKeys(tea.KeyEnter.String()),
+		key.WithHelp(tea.KeyEnter.String(), "send message"),
+	),
+	Close: key.NewBinding(
+		key.WithKeys(tea.KeyEsc.String()),
+		key.WithHelp(tea.KeyEsc.String(), "close connection"),
+	),
+}
+
+// Init sends scripted messages of the request in order and starts listening to received ones.
+// Awaiting replies is left to the user.
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, m.sendAll(m.scripted), waitForFrame(m.conn))
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var (
+		cmd  tea.Cmd
+		cmds []tea.Cmd
+	)
+
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.Viewport.Width = int(float64(msg.Width) * m.wPercent)
+		m.Viewport.Height = int(float64(msg.Height)*m.hPercent) - TOP_NAVIGATION_HEIGHT - INPUT_HEIGHT
+		m.input.Width = m.Viewport.Width - RENDER_LINE_MARGIN
+		m.render()
+		return m, nil
+
+	case frameMsg:
+		m.append(msg.frame)
+		if msg.frame.Sent {
+			return m, nil
+		}
+		return m, waitForFrame(m.conn)
+
+	case disconnectedMsg:
+		m.connected = false
+		status := "Connection closed"
+		if msg.err != nil {
+			status = fmt.Sprintf("Connection closed: %v", msg.err)
+		}
+		m.lines = append(m.lines, print.SprintFaint(status))
+		m.plainLines = append(m.plainLines, status)
+		m.render()
+		return m, nil
+
+	case sendFailedMsg:
+		status := fmt.Sprintf("Failed to send: %v", msg.err)
+		m.lines = append(m.lines, print.SprintFaint(status))
+		m.plainLines = append(m.plainLines, status)
+		m.render()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyEsc:
+			return m, m.close()
+		case tea.KeyEnter:
+			data := m.input.Value()
+			if !m.connected || len(data) == 0 {
+				return m, nil
+			}
+			m.input.Reset()
+			return m, m.sendAll([]string{data})
+		case tea.KeyPgUp, tea.KeyPgDown, tea.KeyUp, tea.KeyDown:
+			m.Viewport, cmd = m.Viewport.Update(msg)
+			return m, cmd
+		}
+	}
+
+	m.input, cmd = m.input.Update(msg)
+	cmds = append(cmds, cmd)
+	return m, tea.Batch(cmds...)
+}
+
+func (m Model) View() string {
+	state := lipgloss.NewStyle().Faint(true).Render("closed")
+	if m.connected {
+		state = lipgloss.NewStyle().Foreground(styles.LoadTheme().ResponseStatus200FgColor).Render("connected")
+	}
+	title := fmt.Sprintf("%s %s [%s]", m.requestName, m.url, state)
+
+	views := []string{
+		lipgloss.NewStyle().Width(m.Viewport.Width).Align(lipgloss.Center).Render(title),
+		contentStyle.Render(m.Viewport.View()),
+		inputStyle.Width(m.Viewport.Width - 2).Render(m.input.View()),
+	}
+	joined := lipgloss.JoinVertical(lipgloss.Top, views...)
+	return lipgloss.NewStyle().BorderForeground(styles.LoadTheme().BorderFgColor).Border(lipgloss.RoundedBorder(), true, true).Render(joined)
+}
+
+func (m *Model) append(frame model.WebSocketFrame) {
+	plain, pretty, err := print.SprintWebSocketFrame(frame, m.pretty)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to print websocket message")
+		pretty = plain
+	}
+	if !m.pretty {
+		pretty = plain
+	}
+	m.lines = append(m.lines, pretty)
+	m.plainLines = append(m.plainLines, plain)
+	m.render()
+}
+
+func (m *Model) render() {
+	width := m.Viewport.Width - RENDER_LINE_MARGIN
+	var wrapped []string
+	for _, line := range m.lines {
+		if lipgloss.Width(line) >= width {
+			line = wrap.String(line, width)
+		}
+		wrapped = append(wrapped, line)
+	}
+	m.Viewport.SetContent(strings.Join(wrapped, "\n"))
+	m.Viewport.GotoBottom()
+}
+
+func (m Model) sendAll(messages []string) tea.Cmd {
+	conn := m.conn
+	var cmds []tea.Cmd
+	for _, data := range messages {
+		cmds = append(cmds, func() tea.Msg {
+			frame, err := conn.Send(data)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to send websocket message")
+				return sendFailedMsg{err: err}
+			}
+			return frameMsg{frame: frame}
+		})
+	}
+	return tea.Sequence(cmds...)
+}
+
+func (m Model) close() tea.Cmd {
+	conn := m.conn
+	msg := WebSocketClosedMsg{
+		RequestName: m.requestName,
+		RunAt:       m.runAt,
+		Results:     strings.Join(m.lines, "\n"),
+		RawResults:  strings.Join(m.plainLines, "\n"),
+	}
+	return func() tea.Msg {
+		if err := conn.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close websocket connection")
+		}
+		return msg
+	}
+}
+
+func waitForFrame(conn *client.WebSocketConn) tea.Cmd {
+	return func() tea.Msg {
+		frame, ok := <-conn.Received()
+		if !ok {
+			return disconnectedMsg{err: conn.Err()}
+		}
+		return frameMsg{frame: frame}
+	}
+}
+
+func New(requestName string, request model.Request, conn *client.WebSocketConn, pretty bool, w, h int, wPercent, hPercent float64) Model {
+	width := int(float64(w) * wPercent)
+	height := int(float64(h) * hPercent)
+
+	v := viewport.New(width, height-TOP_NAVIGATION_HEIGHT-INPUT_HEIGHT)
+	v.Style = v.Style.Padding(0, 0)
+
+	input := textinput.New()
+	input.Placeholder = "Message to send"
+	input.Prompt = "> "
+	input.Width = width - RENDER_LINE_MARGIN
+	input.Focus()
+
+	var scripted []string
+	for _, message := range request.Messages {
+		scripted = append(scripted, message.Send)
+	}
+
+	return Model{
+		requestName: requestName,
+		url:         request.Url,
+		conn:        conn,
+		scripted:    scripted,
+		runAt:       time.Now(),
+		pretty:      pretty,
+		connected:   true,
+		Viewport:    v,
+		input:       input,
+		wPercent:    wPercent,
+		hPercent:    hPercent,
+		keyMap:      keys,
+	}
+}